@@ -0,0 +1,293 @@
+package biorm
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// MaxBatchSize 批量新增、更新、删除接口单次请求的最大记录数
+const MaxBatchSize = 500
+
+// MaxBatchGetSize 批量获取记录接口单次请求的最大记录数
+const MaxBatchGetSize = 100
+
+// Record 批量更新使用的记录
+type Record struct {
+	RecordId string
+	Fields   map[string]interface{}
+}
+
+// ChunkError 分批请求中某一批次的错误
+type ChunkError struct {
+	Index     int      // 批次序号，从 0 开始
+	Offset    int      // 该批次第一条记录在输入中的下标
+	Size      int      // 该批次的记录数
+	RecordIds []string // 该批次涉及的记录 ID，新增记录时为空
+	Err       error
+
+	ApiResp   *larkcore.ApiResp
+	CodeError *larkcore.CodeError
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("第 %d 批（offset=%d，%d 条记录）失败：%v", e.Index, e.Offset, e.Size, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError 分批请求中有部分批次失败，成功批次的结果仍会正常返回
+type BatchError struct {
+	Total  int // 批次总数
+	Chunks []*ChunkError
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, 0, len(e.Chunks))
+	for _, c := range e.Chunks {
+		msgs = append(msgs, c.Error())
+	}
+	return fmt.Sprintf("%d/%d 个批次失败：%s", len(e.Chunks), e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() error {
+	if len(e.Chunks) == 0 {
+		return nil
+	}
+	return e.Chunks[0]
+}
+
+// MissingRecordsError 批量获取记录时部分记录不存在或无权限访问
+type MissingRecordsError struct {
+	AbsentRecordIds    []string // 不存在的记录
+	ForbiddenRecordIds []string // 无权限访问的记录（针对开启了高级权限的文档）
+}
+
+func (e *MissingRecordsError) Error() string {
+	return fmt.Sprintf("%d 条记录不存在，%d 条记录无权限访问：absent=%v, forbidden=%v",
+		len(e.AbsentRecordIds), len(e.ForbiddenRecordIds), e.AbsentRecordIds, e.ForbiddenRecordIds)
+}
+
+func (e *MissingRecordsError) Unwrap() error {
+	return ErrRecordNotFound
+}
+
+// chunkRanges 按 size 切分长度为 n 的输入，返回每一批的 [start, end)
+func chunkRanges(n, size int) [][2]int {
+	if size <= 0 {
+		size = n
+	}
+	ranges := make([][2]int, 0, (n+size-1)/size)
+	for start := 0; start < n; start += size {
+		end := start + size
+		if end > n {
+			end = n
+		}
+		ranges = append(ranges, [2]int{start, end})
+	}
+	return ranges
+}
+
+// chunkClientToken 根据幂等 token 为每个批次派生固定的 uuid，第一个批次直接使用原 token
+func chunkClientToken(clientToken string, index int) string {
+	if clientToken == "" || index == 0 {
+		return clientToken
+	}
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s#%d", clientToken, index)))
+	sum[6] = (sum[6] & 0x0f) | 0x50 // version 5
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// BatchUpdate 批量更新记录，超过 MaxBatchSize 条时自动分批请求
+// 部分批次失败时 tx.Error 为 *BatchError，data 中包含成功批次的结果
+func (db *DB) BatchUpdate(records []Record) (data []*larkbitable.AppTableRecord, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	for _, r := range records {
+		if r.RecordId == "" {
+			tx.Error = ErrRecordIdRequired
+			return
+		}
+	}
+
+	ranges := chunkRanges(len(records), MaxBatchSize)
+	batchErr := &BatchError{Total: len(ranges)}
+	for i, rg := range ranges {
+		if i > 0 {
+			if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+				tx.Error = err
+				return
+			}
+		}
+
+		chunk := records[rg[0]:rg[1]]
+		list := make([]*larkbitable.AppTableRecord, 0, len(chunk))
+		recordIds := make([]string, 0, len(chunk))
+		for _, r := range chunk {
+			list = append(list, larkbitable.NewAppTableRecordBuilder().RecordId(r.RecordId).Fields(r.Fields).Build())
+			recordIds = append(recordIds, r.RecordId)
+		}
+
+		req := larkbitable.NewBatchUpdateAppTableRecordReqBuilder().
+			AppToken(tx.AppToken).TableId(tx.TableId).
+			UserIdType(tx.Statement.UserIdType).
+			Body(larkbitable.NewBatchUpdateAppTableRecordReqBodyBuilder().
+				Records(list).
+				Build()).
+			Build()
+
+		// 发起请求
+		var resp *larkbitable.BatchUpdateAppTableRecordResp
+		err := tx.execute(apiCall{
+			Method:     http.MethodPost,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records/batch_update",
+			Body:       req.Body,
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTableRecord.BatchUpdate(ctx, req)
+			return resp, err
+		})
+
+		// 处理错误
+		chunkErr := &ChunkError{Index: i, Offset: rg[0], Size: len(chunk), RecordIds: recordIds}
+		if err == nil && resp == nil {
+			err = ErrResponseIsNil
+		}
+		if resp != nil {
+			chunkErr.ApiResp = resp.ApiResp
+			chunkErr.CodeError = &resp.CodeError
+			if err == nil && !resp.Success() {
+				err = resp.CodeError
+			}
+		}
+		if err != nil {
+			chunkErr.Err = err
+			batchErr.Chunks = append(batchErr.Chunks, chunkErr)
+			if ctxErr := tx.Statement.Context.Err(); ctxErr != nil {
+				break
+			}
+			continue
+		}
+
+		if resp.Data != nil {
+			data = append(data, resp.Data.Records...)
+			tx.RowsAffected += int64(len(resp.Data.Records))
+		}
+	}
+
+	if len(batchErr.Chunks) > 0 {
+		tx.Error = batchErr
+	}
+	return
+}
+
+// BatchDelete 批量删除记录，超过 MaxBatchSize 条时自动分批请求
+// 部分批次失败时 tx.Error 为 *BatchError，data 中包含成功批次的结果
+func (db *DB) BatchDelete(recordIds []string) (data []*larkbitable.DeleteRecord, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	for _, id := range recordIds {
+		if id == "" {
+			tx.Error = ErrRecordIdRequired
+			return
+		}
+	}
+
+	ranges := chunkRanges(len(recordIds), MaxBatchSize)
+	batchErr := &BatchError{Total: len(ranges)}
+	for i, rg := range ranges {
+		if i > 0 {
+			if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+				tx.Error = err
+				return
+			}
+		}
+
+		chunk := recordIds[rg[0]:rg[1]]
+		req := larkbitable.NewBatchDeleteAppTableRecordReqBuilder().
+			AppToken(tx.AppToken).TableId(tx.TableId).
+			Body(larkbitable.NewBatchDeleteAppTableRecordReqBodyBuilder().
+				Records(chunk).
+				Build()).
+			Build()
+
+		// 发起请求
+		var resp *larkbitable.BatchDeleteAppTableRecordResp
+		err := tx.execute(apiCall{
+			Method:     http.MethodPost,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records/batch_delete",
+			Body:       req.Body,
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTableRecord.BatchDelete(ctx, req)
+			return resp, err
+		})
+
+		// 处理错误
+		chunkErr := &ChunkError{Index: i, Offset: rg[0], Size: len(chunk), RecordIds: chunk}
+		if err == nil && resp == nil {
+			err = ErrResponseIsNil
+		}
+		if resp != nil {
+			chunkErr.ApiResp = resp.ApiResp
+			chunkErr.CodeError = &resp.CodeError
+			if err == nil && !resp.Success() {
+				err = resp.CodeError
+			}
+		}
+		if err != nil {
+			chunkErr.Err = err
+			batchErr.Chunks = append(batchErr.Chunks, chunkErr)
+			if ctxErr := tx.Statement.Context.Err(); ctxErr != nil {
+				break
+			}
+			continue
+		}
+
+		if resp.Data != nil {
+			data = append(data, resp.Data.Records...)
+			for _, r := range resp.Data.Records {
+				if r != nil && (r.Deleted == nil || *r.Deleted) {
+					tx.RowsAffected++
+				}
+			}
+		}
+	}
+
+	if len(batchErr.Chunks) > 0 {
+		tx.Error = batchErr
+	}
+	return
+}
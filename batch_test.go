@@ -0,0 +1,310 @@
+package biorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+	"github.com/2015WUJI01/biorm/logger"
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+)
+
+// batchTask 批量写入测试使用的模型
+type batchTask struct {
+	RecordId string `biorm:"record_id"`
+	Title    string `biorm:"名称"`
+}
+
+// newBatchTestDB 返回选中测试数据表的 DB，数据表中有一个名称字段，写入其它字段时接口返回错误
+func newBatchTestDB(srv *biormtest.Server) *DB {
+	srv.AddTable(testAppToken, testTableId, "任务")
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Name: "名称", Type: 1, IsPrimary: true})
+	return newServerDB(srv).Base(testAppToken).Table(testTableId)
+}
+
+// batchRequests 返回指定批量接口每次请求的记录数与 client_token
+func batchRequests(srv *biormtest.Server, action string) (sizes []int, tokens []string) {
+	for _, r := range srv.Requests() {
+		if !strings.HasSuffix(r.Path, "/records/"+action) {
+			continue
+		}
+		var body struct {
+			Records []interface{} `json:"records"`
+		}
+		_ = json.Unmarshal(r.Body, &body)
+		sizes = append(sizes, len(body.Records))
+		query, _ := url.ParseQuery(r.Query)
+		tokens = append(tokens, query.Get("client_token"))
+	}
+	return
+}
+
+func TestChunkRanges(t *testing.T) {
+	got := chunkRanges(1201, MaxBatchSize)
+	want := [][2]int{{0, 500}, {500, 1000}, {1000, 1201}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chunkRanges = %v, want %v", got, want)
+	}
+	if got := chunkRanges(0, MaxBatchSize); len(got) != 0 {
+		t.Errorf("chunkRanges(0) = %v, want empty", got)
+	}
+}
+
+func TestUpdatesAndDeleteRequireCondition(t *testing.T) {
+	db := NewDB(nil).Base(testAppToken).Table(testTableId)
+
+	if _, tx := db.Delete(); !errors.Is(tx.Error, ErrMissingWhereClause) {
+		t.Errorf("Delete() error = %v, want ErrMissingWhereClause", tx.Error)
+	}
+	if tx := db.Updates(map[string]interface{}{"状态": "归档"}); !errors.Is(tx.Error, ErrMissingWhereClause) {
+		t.Errorf("Updates() error = %v, want ErrMissingWhereClause", tx.Error)
+	}
+}
+
+func TestChunkClientToken(t *testing.T) {
+	const token = "fe599b60-450f-46ff-b2ef-9f6675625b97"
+	if got := chunkClientToken(token, 0); got != token {
+		t.Errorf("chunk 0 token = %q, want original token", got)
+	}
+	if got := chunkClientToken("", 3); got != "" {
+		t.Errorf("empty token = %q, want empty", got)
+	}
+
+	first, second := chunkClientToken(token, 1), chunkClientToken(token, 2)
+	if first == second || first == token {
+		t.Errorf("chunk tokens must be distinct, got %q and %q", first, second)
+	}
+	if first != chunkClientToken(token, 1) {
+		t.Error("chunk token must be deterministic")
+	}
+	if len(first) != 36 || first[14] != '5' {
+		t.Errorf("chunk token %q is not a version 5 uuid", first)
+	}
+}
+
+func TestCreateInBatches(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+
+	tasks := make([]batchTask, 1201)
+	for i := range tasks {
+		tasks[i].Title = fmt.Sprintf("任务 %d", i)
+	}
+	data, tx := db.CreateInBatches(tasks, 0)
+	if tx.Error != nil || len(data) != 1201 || tx.RowsAffected != 1201 {
+		t.Fatalf("CreateInBatches = %d records, rows %d, err %v", len(data), tx.RowsAffected, tx.Error)
+	}
+	if sizes, _ := batchRequests(srv, "batch_create"); !reflect.DeepEqual(sizes, []int{500, 500, 201}) {
+		t.Errorf("batch sizes = %v, want [500 500 201]", sizes)
+	}
+	if tasks[0].RecordId != *data[0].RecordId || tasks[1200].RecordId != *data[1200].RecordId {
+		t.Errorf("record ids not written back: %q, %q", tasks[0].RecordId, tasks[1200].RecordId)
+	}
+	if records := srv.Records(testAppToken, testTableId); len(records) != 1201 {
+		t.Errorf("stored %d records, want 1201", len(records))
+	}
+}
+
+func TestCreateInBatchesPartialFailure(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+
+	records := []map[string]interface{}{
+		{"名称": "a"}, {"名称": "b"},
+		{"名称": "c"}, {"不存在": "d"},
+		{"名称": "e"},
+	}
+	data, tx := db.CreateInBatches(records, 2)
+	var batchErr *BatchError
+	if !errors.As(tx.Error, &batchErr) || batchErr.Total != 3 || len(batchErr.Chunks) != 1 {
+		t.Fatalf("CreateInBatches error = %v, want one failed chunk out of 3", tx.Error)
+	}
+	chunk := batchErr.Chunks[0]
+	if chunk.Index != 1 || chunk.Offset != 2 || chunk.Size != 2 || chunk.CodeError == nil || chunk.CodeError.Code != biormtest.CodeFieldNameNotFound {
+		t.Errorf("chunk = %+v", chunk)
+	}
+	if !strings.Contains(chunk.Error(), "offset=2，2 条记录") {
+		t.Errorf("chunk error = %q", chunk.Error())
+	}
+	if len(data) != 3 || tx.RowsAffected != 3 || len(srv.Records(testAppToken, testTableId)) != 3 {
+		t.Errorf("created %d records, rows %d, want the other chunks to succeed", len(data), tx.RowsAffected)
+	}
+}
+
+func TestCreateInBatchesRetryKeepsClientToken(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	newBatchTestDB(srv)
+
+	// 第二个批次第一次请求写入成功但响应丢失，重试时应使用同一个 client_token
+	creates := 0
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/records/batch_create") {
+			if creates++; creates == 2 {
+				srv.ServeHTTP(httptest.NewRecorder(), r)
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	db := NewDB(srv.Client(lark.WithOpenBaseUrl(flaky.URL)))
+	db.Config.RequestInterval = 0
+	db.Config.Logger = logger.Discard
+	db.Config.Retry = &RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, AutoClientToken: true}
+
+	tasks := make([]batchTask, 5)
+	_, tx := db.Base(testAppToken).Table(testTableId).CreateInBatches(tasks, 2)
+	if tx.Error != nil || tx.RowsAffected != 5 {
+		t.Fatalf("CreateInBatches rows %d, err %v", tx.RowsAffected, tx.Error)
+	}
+	_, tokens := batchRequests(srv, "batch_create")
+	if len(tokens) != 4 || tokens[1] != tokens[2] || tokens[0] == tokens[1] || tokens[2] == tokens[3] || tokens[0] == "" {
+		t.Errorf("client tokens = %q, want the retried chunk to reuse its token", tokens)
+	}
+	if records := srv.Records(testAppToken, testTableId); len(records) != 5 {
+		t.Errorf("stored %d records, want 5 without duplicates", len(records))
+	}
+}
+
+func TestBatchGet(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+	db.Config.Concurrency = 3
+
+	fields := make([]map[string]interface{}, 250)
+	for i := range fields {
+		fields[i] = map[string]interface{}{"名称": fmt.Sprintf("任务 %d", i)}
+	}
+	ids := srv.Insert(testAppToken, testTableId, fields...)
+
+	// 倒序传入，结果仍按传入顺序返回
+	reversed := make([]string, 0, len(ids))
+	for i := len(ids) - 1; i >= 0; i-- {
+		reversed = append(reversed, ids[i])
+	}
+	data, tx := db.BatchGet(reversed)
+	if tx.Error != nil || len(data) != 250 {
+		t.Fatalf("BatchGet = %d records, err %v", len(data), tx.Error)
+	}
+	for i, record := range data {
+		if *record.RecordId != reversed[i] {
+			t.Fatalf("record %d = %s, want %s", i, *record.RecordId, reversed[i])
+		}
+	}
+	if sizes, _ := batchRequests(srv, "batch_get"); len(sizes) != 3 {
+		t.Errorf("requested %d chunks, want 3", len(sizes))
+	}
+
+	data, tx = db.BatchGet([]string{ids[0], "recMissing", ids[1]})
+	var missing *MissingRecordsError
+	if !errors.As(tx.Error, &missing) || !errors.Is(tx.Error, ErrRecordNotFound) || !reflect.DeepEqual(missing.AbsentRecordIds, []string{"recMissing"}) {
+		t.Errorf("BatchGet(missing) error = %v", tx.Error)
+	}
+	if len(data) != 2 || *data[0].RecordId != ids[0] || *data[1].RecordId != ids[1] {
+		t.Errorf("BatchGet(missing) = %d records", len(data))
+	}
+
+	if _, tx := db.BatchGet(nil); !errors.Is(tx.Error, ErrRecordIdsRequired) {
+		t.Errorf("BatchGet(nil) error = %v, want ErrRecordIdsRequired", tx.Error)
+	}
+}
+
+func TestBatchGetConcurrentHonorsContext(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+	db.Config.Concurrency = 3
+	db.Config.RequestInterval = time.Hour
+
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("rec%08d", i)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, tx := db.WithContext(ctx).BatchGet(ids); !errors.Is(tx.Error, context.DeadlineExceeded) {
+		t.Errorf("BatchGet error = %v, want context.DeadlineExceeded", tx.Error)
+	}
+	if sizes, _ := batchRequests(srv, "batch_get"); len(sizes) != 1 {
+		t.Errorf("requested %d chunks, want only the first chunk before the interval", len(sizes))
+	}
+}
+
+func TestBatchUpdateAndDelete(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+
+	fields := make([]map[string]interface{}, 1201)
+	for i := range fields {
+		fields[i] = map[string]interface{}{"名称": fmt.Sprintf("任务 %d", i)}
+	}
+	ids := srv.Insert(testAppToken, testTableId, fields...)
+
+	records := make([]Record, len(ids))
+	for i, id := range ids {
+		records[i] = Record{RecordId: id, Fields: map[string]interface{}{"名称": "已归档"}}
+	}
+	data, tx := db.BatchUpdate(records)
+	if tx.Error != nil || len(data) != 1201 || tx.RowsAffected != 1201 {
+		t.Fatalf("BatchUpdate = %d records, rows %d, err %v", len(data), tx.RowsAffected, tx.Error)
+	}
+	if sizes, _ := batchRequests(srv, "batch_update"); !reflect.DeepEqual(sizes, []int{500, 500, 201}) {
+		t.Errorf("update batch sizes = %v, want [500 500 201]", sizes)
+	}
+
+	// 第二批中有不存在的记录，只有该批次失败
+	records[700].RecordId = "recMissing"
+	data, tx = db.BatchUpdate(records)
+	var batchErr *BatchError
+	if !errors.As(tx.Error, &batchErr) || batchErr.Total != 3 || len(batchErr.Chunks) != 1 {
+		t.Fatalf("BatchUpdate error = %v, want one failed chunk out of 3", tx.Error)
+	}
+	chunk := batchErr.Chunks[0]
+	if chunk.Index != 1 || chunk.Offset != 500 || chunk.Size != 500 || len(chunk.RecordIds) != 500 || chunk.RecordIds[200] != "recMissing" ||
+		chunk.CodeError == nil || chunk.CodeError.Code != biormtest.CodeRecordNotFound {
+		t.Errorf("update chunk = %+v", chunk)
+	}
+	if len(data) != 701 || tx.RowsAffected != 701 {
+		t.Errorf("BatchUpdate = %d records, rows %d, want 701", len(data), tx.RowsAffected)
+	}
+
+	srv.ResetRequests()
+	deleted, tx := db.BatchDelete(append([]string{"recMissing"}, ids[1:]...))
+	if !errors.As(tx.Error, &batchErr) || len(batchErr.Chunks) != 1 || batchErr.Chunks[0].Index != 0 || batchErr.Chunks[0].Size != 500 {
+		t.Fatalf("BatchDelete error = %v, want the first chunk to fail", tx.Error)
+	}
+	if len(deleted) != 701 || tx.RowsAffected != 701 || len(srv.Records(testAppToken, testTableId)) != 500 {
+		t.Errorf("BatchDelete = %d records, rows %d, %d left", len(deleted), tx.RowsAffected, len(srv.Records(testAppToken, testTableId)))
+	}
+	if sizes, _ := batchRequests(srv, "batch_delete"); !reflect.DeepEqual(sizes, []int{500, 500, 201}) {
+		t.Errorf("delete batch sizes = %v, want [500 500 201]", sizes)
+	}
+
+	// 空输入不发起请求
+	srv.ResetRequests()
+	if data, tx := db.BatchUpdate(nil); tx.Error != nil || len(data) != 0 || tx.RowsAffected != 0 {
+		t.Errorf("BatchUpdate(nil) = %d records, err %v", len(data), tx.Error)
+	}
+	if data, tx := db.BatchDelete(nil); tx.Error != nil || len(data) != 0 || tx.RowsAffected != 0 {
+		t.Errorf("BatchDelete(nil) = %d records, err %v", len(data), tx.Error)
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Errorf("empty input sent %d requests", len(requests))
+	}
+}
@@ -3,9 +3,10 @@ package biorm
 import (
 	"context"
 	"fmt"
-	"log"
+	"reflect"
 	"time"
 
+	"github.com/2015WUJI01/biorm/logger"
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
@@ -15,10 +16,41 @@ type Config struct {
 
 	// 每次请求的间隔时间，单位为毫秒，默认为 1s
 	RequestInterval time.Duration
+
+	// 是否允许在没有任何条件时执行 Updates、Delete，默认为 false
+	AllowGlobalUpdate bool
+
+	// 分批读取时的最大并发请求数，默认为 1，即按 RequestInterval 间隔依次请求；
+	// 大于 1 时请求仍间隔 RequestInterval 发出，但不等待上一个请求返回
+	Concurrency int
+
+	// 日志，默认为 logger.Default，只输出警告及以上级别
+	Logger logger.Interface
+
+	// 每秒允许的最大请求数，同一个 NewDB 创建的实例共享限流，为 0 时不限流
+	RateLimit float64
+
+	// 限流允许的突发请求数，默认与 RateLimit 相同
+	RateBurst int
+
+	// 请求失败后的重试策略，默认为 DefaultRetryPolicy()，为 nil 时不重试
+	Retry *RetryPolicy
+
+	// 查询前是否检查 Select、Order 与查询条件中的字段是否存在，并按字段类型检查运算符、编码条件的值，
+	// 字段列表会被缓存，通过本库新增、更新、删除字段后自动失效，在其它地方修改字段后需要调用 InvalidateCache，
+	// 默认为 false
+	ValidateFields bool
+
+	// 开放接口的域名，例如 lark.LarkBaseUrl、私有化部署或测试服务器的地址，作用于全部请求（包括获取 access token），
+	// 为空时使用 lark.Client 配置的域名（lark.WithOpenBaseUrl），默认为空
+	BaseUrl string
 }
 
 type DB struct {
-	cli *lark.Client
+	cli     *lark.Client
+	limiter *rateLimiter
+	cache   *metaCache
+	apis    *apiClients
 	*Config
 
 	// op values
@@ -28,16 +60,22 @@ type DB struct {
 	TableId  string
 	ViewId   string
 
-	ApiResp   *larkcore.ApiResp
-	CodeError *larkcore.CodeError
-	Error     error
+	ApiResp      *larkcore.ApiResp
+	CodeError    *larkcore.CodeError
+	Error        error
+	RowsAffected int64 // 批量写入、删除成功的记录数
 }
 
 func NewDB(cli *lark.Client) *DB {
 	db := &DB{
-		cli: cli,
+		cli:     cli,
+		limiter: &rateLimiter{},
+		cache:   &metaCache{},
+		apis:    &apiClients{},
 		Config: &Config{
 			RequestInterval: 1 * time.Second,
+			Logger:          logger.Default,
+			Retry:           DefaultRetryPolicy(),
 		},
 	}
 	db.Statement = Statement{
@@ -60,10 +98,13 @@ func (db *DB) Clone() *DB {
 		return db
 	}
 
-	log.Printf("[Clone调试] 开始克隆实例")
+	db.debugf("[Clone] 开始克隆实例")
 
 	newDb := &DB{
 		cli:       db.cli,
+		limiter:   db.limiter,
+		cache:     db.cache,
+		apis:      db.apis,
 		Config:    db.Config,
 		AppToken:  db.AppToken,
 		TableId:   db.TableId,
@@ -75,16 +116,21 @@ func (db *DB) Clone() *DB {
 
 	// 创建新的 Statement，并复制所有条件
 	newDb.Statement = Statement{
-		DB:              newDb,
-		Context:         db.Statement.Context,
-		AppToken:        db.Statement.AppToken,
-		TableId:         db.Statement.TableId,
-		UserIdType:      db.Statement.UserIdType,
-		AutomaticFields: db.Statement.AutomaticFields,
-		Idempotent:      db.Statement.Idempotent,
-		ClientToken:     db.Statement.ClientToken,
-		Dest:            db.Statement.Dest,
-		Selects:         make([]string, len(db.Statement.Selects)),
+		DB:                newDb,
+		Context:           db.Statement.Context,
+		AppToken:          db.Statement.AppToken,
+		TableId:           db.Statement.TableId,
+		UserIdType:        db.Statement.UserIdType,
+		AutomaticFields:   db.Statement.AutomaticFields,
+		Idempotent:        db.Statement.Idempotent,
+		ClientToken:       db.Statement.ClientToken,
+		AllowGlobalUpdate: db.Statement.AllowGlobalUpdate,
+		PageSize:          db.Statement.PageSize,
+		PageToken:         db.Statement.PageToken,
+		Limit:             db.Statement.Limit,
+		Offset:            db.Statement.Offset,
+		Dest:              db.Statement.Dest,
+		Selects:           make([]string, len(db.Statement.Selects)),
 	}
 
 	// 复制 Selects
@@ -93,40 +139,7 @@ func (db *DB) Clone() *DB {
 	}
 
 	// 复制 Filter
-	if db.Statement.Filter.Conjunction != nil {
-		conjunction := *db.Statement.Filter.Conjunction
-		newDb.Statement.Filter.Conjunction = &conjunction
-
-		// 深度复制所有条件
-		if len(db.Statement.Filter.Conditions) > 0 {
-			newDb.Statement.Filter.Conditions = make([]*larkbitable.Condition, len(db.Statement.Filter.Conditions))
-			for i, cond := range db.Statement.Filter.Conditions {
-				if cond != nil {
-					newCond := &larkbitable.Condition{}
-					if cond.FieldName != nil {
-						fieldName := *cond.FieldName
-						newCond.FieldName = &fieldName
-					}
-					if cond.Operator != nil {
-						operator := *cond.Operator
-						newCond.Operator = &operator
-					}
-					if len(cond.Value) > 0 {
-						newCond.Value = make([]string, len(cond.Value))
-						copy(newCond.Value, cond.Value)
-					}
-					newDb.Statement.Filter.Conditions[i] = newCond
-				}
-			}
-		}
-	} else {
-		// 初始化空的过滤条件
-		and := "and"
-		newDb.Statement.Filter = larkbitable.FilterInfo{
-			Conjunction: &and,
-			Conditions:  make([]*larkbitable.Condition, 0),
-		}
-	}
+	newDb.Statement.Filter = copyFilter(db.Statement.Filter)
 
 	// 复制Sort
 	if len(db.Statement.Sort) > 0 {
@@ -147,11 +160,11 @@ func (db *DB) Clone() *DB {
 		}
 	}
 
-	log.Printf("[Clone调试] 克隆完成，Filter: conjunction=%v, conditions长度=%d",
-		newDb.Statement.Filter.Conjunction, len(newDb.Statement.Filter.Conditions))
+	db.debugf("[Clone] 克隆完成，Filter: conjunction=%s, conditions长度=%d, children长度=%d",
+		filterConjunction(newDb.Statement.Filter), len(newDb.Statement.Filter.Conditions), len(newDb.Statement.Filter.Children))
 	for i, cond := range newDb.Statement.Filter.Conditions {
 		if cond != nil && cond.FieldName != nil && cond.Operator != nil {
-			log.Printf("[Clone调试] 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
+			db.debugf("[Clone] 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
 				i, *cond.FieldName, *cond.Operator, cond.Value)
 		}
 	}
@@ -165,7 +178,7 @@ func (db *DB) Finalize() {
 		return
 	}
 
-	log.Printf("[Finalize调试] 开始释放实例资源")
+	db.debugf("[Finalize] 开始释放实例资源")
 
 	// 清理Statement中的资源
 	if len(db.Statement.Selects) > 0 {
@@ -178,6 +191,7 @@ func (db *DB) Finalize() {
 		}
 		db.Statement.Filter.Conditions = nil
 	}
+	db.Statement.Filter.Children = nil
 
 	if len(db.Statement.Sort) > 0 {
 		for i := range db.Statement.Sort {
@@ -190,7 +204,7 @@ func (db *DB) Finalize() {
 	db.Statement.Dest = nil
 	db.ApiResp = nil
 
-	log.Printf("[Finalize调试] 实例资源释放完成")
+	db.debugf("[Finalize] 实例资源释放完成")
 }
 
 // hasError 检查是否有错误，避免在有错误的情况下继续操作
@@ -198,6 +212,94 @@ func (db *DB) hasError() bool {
 	return db.Error != nil
 }
 
+// getLogger 返回当前实例使用的日志
+func (db *DB) getLogger() logger.Interface {
+	if db.Config == nil || db.Config.Logger == nil {
+		return logger.Default
+	}
+	return db.Config.Logger
+}
+
+// debugf 输出调试日志
+func (db *DB) debugf(format string, args ...interface{}) {
+	db.getLogger().Debug(db.Statement.Context, format, args...)
+}
+
+// trace 输出一次 API 请求的日志，resp 为 *larkcore.ApiResp 或 SDK 返回的响应
+func (db *DB) trace(begin time.Time, method, path string, body interface{}, resp interface{}, err error) {
+	db.getLogger().Trace(db.Statement.Context, begin, func() logger.TraceInfo {
+		info := logger.TraceInfo{Method: method, Path: path, RequestBody: body}
+		if apiResp := apiRespOf(resp); apiResp != nil {
+			info.StatusCode = apiResp.StatusCode
+			info.LogId = apiResp.LogId()
+			info.ResponseBody = apiResp.RawBody
+		}
+		return info
+	}, err)
+}
+
+// apiRespOf 从 SDK 返回的响应中取出 *larkcore.ApiResp
+func apiRespOf(resp interface{}) *larkcore.ApiResp {
+	if r, ok := resp.(*larkcore.ApiResp); ok {
+		return r
+	}
+	v := reflectStruct(resp)
+	if !v.IsValid() {
+		return nil
+	}
+	if f := v.FieldByName("ApiResp"); f.IsValid() {
+		if r, ok := f.Interface().(*larkcore.ApiResp); ok {
+			return r
+		}
+	}
+	return nil
+}
+
+// checkResponse 检查 SDK 返回的响应，失败时设置 Error、ApiResp 与 CodeError 并返回 false
+func (db *DB) checkResponse(resp interface{}, err error) bool {
+	if apiResp := apiRespOf(resp); apiResp != nil {
+		db.ApiResp = apiResp
+	}
+	if err == nil && !reflectStruct(resp).IsValid() {
+		err = ErrResponseIsNil
+	}
+	if codeErr := codeErrorOf(resp); codeErr != nil && codeErr.Code != 0 {
+		db.CodeError = codeErr
+		if err == nil {
+			err = codeErr
+		}
+	}
+	if err != nil {
+		db.Error = err
+		return false
+	}
+	return true
+}
+
+// reflectStruct 返回结构体指针指向的结构体，resp 不是非 nil 的结构体指针时返回无效值
+func reflectStruct(resp interface{}) reflect.Value {
+	v := reflect.ValueOf(resp)
+	if !v.IsValid() || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return v.Elem()
+}
+
+// sleepContext 等待 d 时长，context 被取消时提前返回其错误
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 func (db *DB) ErrorString() string {
 	s := db.Error.Error()
 	if db.ApiResp != nil {
@@ -0,0 +1,315 @@
+package biormtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type condition struct {
+	FieldName string   `json:"field_name"`
+	Operator  string   `json:"operator"`
+	Value     []string `json:"value"`
+}
+
+type filterInfo struct {
+	Conjunction string        `json:"conjunction"`
+	Conditions  []condition   `json:"conditions"`
+	Children    []*filterInfo `json:"children"`
+}
+
+// evaluator 按查询记录接口的规则判断记录是否满足筛选条件
+type evaluator struct {
+	now time.Time
+	loc *time.Location
+}
+
+// match 判断字段是否满足筛选条件，条件不合法时返回错误
+func (e *evaluator) match(f *filterInfo, fields map[string]interface{}) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	if f.Conjunction != "" && f.Conjunction != "and" && f.Conjunction != "or" {
+		return false, fmt.Errorf("invalid conjunction: %s", f.Conjunction)
+	}
+
+	results := make([]bool, 0, len(f.Conditions)+len(f.Children))
+	for _, c := range f.Conditions {
+		ok, err := e.matchCondition(c, fields[c.FieldName])
+		if err != nil {
+			return false, err
+		}
+		results = append(results, ok)
+	}
+	for _, child := range f.Children {
+		if child == nil {
+			continue
+		}
+		if len(child.Children) > 0 {
+			return false, fmt.Errorf("filter children can not be nested")
+		}
+		ok, err := e.match(child, fields)
+		if err != nil {
+			return false, err
+		}
+		results = append(results, ok)
+	}
+
+	if f.Conjunction == "or" {
+		for _, ok := range results {
+			if ok {
+				return true, nil
+			}
+		}
+		return len(results) == 0, nil
+	}
+	for _, ok := range results {
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (e *evaluator) matchCondition(c condition, cell interface{}) (bool, error) {
+	switch c.Operator {
+	case "isEmpty":
+		return isEmpty(cell), nil
+	case "isNotEmpty":
+		return !isEmpty(cell), nil
+	}
+	if len(c.Value) == 0 {
+		return false, fmt.Errorf("operator %s of field %s requires a value", c.Operator, c.FieldName)
+	}
+	if isDateMode(c.Value[0]) {
+		return e.matchDate(c, cell)
+	}
+
+	texts := cellStrings(cell)
+	switch c.Operator {
+	case "is":
+		return equalCell(cell, texts, c.Value), nil
+	case "isNot":
+		return !equalCell(cell, texts, c.Value), nil
+	case "contains":
+		return containsCell(cell, texts, c.Value), nil
+	case "doesNotContain":
+		return !containsCell(cell, texts, c.Value), nil
+	case "isGreater", "isGreaterEqual", "isLess", "isLessEqual":
+		a, ok := cellNumber(cell)
+		b, err := strconv.ParseFloat(c.Value[0], 64)
+		if !ok || err != nil {
+			return false, nil
+		}
+		switch c.Operator {
+		case "isGreater":
+			return a > b, nil
+		case "isGreaterEqual":
+			return a >= b, nil
+		case "isLess":
+			return a < b, nil
+		default:
+			return a <= b, nil
+		}
+	}
+	return false, fmt.Errorf("unsupported operator: %s", c.Operator)
+}
+
+// matchDate 日期条件只支持 is、isGreater、isLess，按文档时区的日期比较
+func (e *evaluator) matchDate(c condition, cell interface{}) (bool, error) {
+	from, to, err := e.dateRange(c.Value)
+	if err != nil {
+		return false, err
+	}
+	ms, ok := cellNumber(cell)
+	if !ok {
+		return false, nil
+	}
+	day := e.day(time.Unix(0, int64(ms)*int64(time.Millisecond)))
+
+	switch c.Operator {
+	case "is":
+		return !day.Before(from) && !day.After(to), nil
+	case "isGreater":
+		return day.After(to), nil
+	case "isLess":
+		return day.Before(from), nil
+	}
+	return false, fmt.Errorf("operator %s is not supported on date field %s", c.Operator, c.FieldName)
+}
+
+// dateRange 返回日期条件表示的日期范围（包含两端）
+func (e *evaluator) dateRange(value []string) (from, to time.Time, err error) {
+	today := e.day(e.now)
+	days := func(a, b int) (time.Time, time.Time, error) {
+		return today.AddDate(0, 0, a), today.AddDate(0, 0, b), nil
+	}
+	weekday := (int(today.Weekday()) + 6) % 7 // 周一为 0
+
+	switch value[0] {
+	case "ExactDate":
+		if len(value) < 2 {
+			return from, to, fmt.Errorf("ExactDate requires a date")
+		}
+		if ms, err := strconv.ParseInt(value[1], 10, 64); err == nil {
+			d := e.day(time.Unix(0, ms*int64(time.Millisecond)))
+			return d, d, nil
+		}
+		t, err := time.ParseInLocation("2006/01/02", value[1], e.loc)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid ExactDate: %s", value[1])
+		}
+		return t, t, nil
+	case "Today":
+		return days(0, 0)
+	case "Tomorrow":
+		return days(1, 1)
+	case "Yesterday":
+		return days(-1, -1)
+	case "CurrentWeek":
+		return days(-weekday, 6-weekday)
+	case "LastWeek":
+		return days(-weekday-7, -weekday-1)
+	case "CurrentMonth":
+		first := today.AddDate(0, 0, 1-today.Day())
+		return first, first.AddDate(0, 1, -1), nil
+	case "LastMonth":
+		first := today.AddDate(0, 0, 1-today.Day()).AddDate(0, -1, 0)
+		return first, first.AddDate(0, 1, -1), nil
+	case "TheLastWeek":
+		return days(-7, 0)
+	case "TheNextWeek":
+		return days(0, 7)
+	case "TheLastMonth":
+		return days(-30, 0)
+	case "TheNextMonth":
+		return days(0, 30)
+	}
+	return from, to, fmt.Errorf("invalid date value: %s", value[0])
+}
+
+// day 返回 t 在文档时区中当天的零点
+func (e *evaluator) day(t time.Time) time.Time {
+	t = t.In(e.loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, e.loc)
+}
+
+func isDateMode(s string) bool {
+	switch s {
+	case "ExactDate", "Today", "Tomorrow", "Yesterday", "CurrentWeek", "LastWeek", "CurrentMonth", "LastMonth",
+		"TheLastWeek", "TheNextWeek", "TheLastMonth", "TheNextMonth":
+		return true
+	}
+	return false
+}
+
+// isEmpty 判断单元格是否为空
+func isEmpty(cell interface{}) bool {
+	switch v := cell.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0 || isEmpty(v["link_record_ids"]) && len(v) == 1
+	}
+	return false
+}
+
+// cellStrings 将单元格转换为用于比较的文本：人员为 ID，关联为记录 ID，超链接为链接，多行文本片段为文本
+func cellStrings(cell interface{}) []string {
+	switch v := cell.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			result = append(result, cellStrings(item)...)
+		}
+		return result
+	case map[string]interface{}:
+		if ids, ok := v["link_record_ids"]; ok {
+			return cellStrings(ids)
+		}
+		for _, key := range []string{"id", "record_id", "link", "text", "name"} {
+			if s, ok := v[key].(string); ok {
+				return []string{s}
+			}
+		}
+	}
+	return []string{fmt.Sprintf("%v", cell)}
+}
+
+// cellNumber 将数字或数字文本转换为 float64
+func cellNumber(cell interface{}) (float64, bool) {
+	switch v := cell.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// equalCell 数字按数值比较，多值单元格（多选、人员等）要求值的集合相同
+func equalCell(cell interface{}, texts, values []string) bool {
+	if n, ok := cell.(float64); ok {
+		v, err := strconv.ParseFloat(values[0], 64)
+		return err == nil && n == v
+	}
+	if _, multi := cell.([]interface{}); !multi {
+		return len(texts) == 1 && texts[0] == values[0]
+	}
+	if len(texts) != len(values) {
+		return false
+	}
+	set := make(map[string]bool, len(texts))
+	for _, t := range texts {
+		set[t] = true
+	}
+	for _, v := range values {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// containsCell 文本包含子串；多值单元格包含任意一个值
+func containsCell(cell interface{}, texts, values []string) bool {
+	_, multi := cell.([]interface{})
+	for _, v := range values {
+		for _, t := range texts {
+			if multi && t == v || !multi && strings.Contains(t, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compareCells 比较两个单元格，用于排序
+func compareCells(a, b interface{}) int {
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(strings.Join(cellStrings(a), ","), strings.Join(cellStrings(b), ","))
+}
@@ -0,0 +1,383 @@
+package biormtest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+const (
+	defaultFieldName = "多行文本" // 通过接口新增数据表且没有设置字段时的索引字段
+	defaultViewName  = "表格"   // 通过接口新增数据表且没有设置 default_view_name 时的默认视图
+	fieldTypeText    = 1
+)
+
+// serveTables 处理数据表列表、新增数据表与批量接口，rest 为 tables 之后的路径
+func (s *Server) serveTables(w http.ResponseWriter, r *http.Request, a *app, rest []string, body []byte) {
+	action := ""
+	if len(rest) > 0 {
+		action = rest[0]
+	}
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		start, end, ok := pageRange(w, r, len(a.order), DefaultPageSize, MaxMetaPageSize)
+		if !ok {
+			return
+		}
+		items := make([]map[string]interface{}, 0, end-start)
+		for _, id := range a.order[start:end] {
+			items = append(items, map[string]interface{}{"table_id": id, "name": a.tables[id].name, "revision": 1})
+		}
+		writeData(w, pageData(items, end, len(a.order)))
+
+	case r.Method == http.MethodPost && action == "":
+		var req struct {
+			Table struct {
+				Name            string   `json:"name"`
+				DefaultViewName string   `json:"default_view_name"`
+				Fields          []*Field `json:"fields"`
+			} `json:"table"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Table.Name == "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "table name is required")
+			return
+		}
+		if findTableByName(a, req.Table.Name) != "" {
+			writeError(w, http.StatusOK, CodeTableNameDuplicated, "TableNameDuplicated")
+			return
+		}
+		id, t := s.createTable(a, req.Table.Name, req.Table.Fields, req.Table.DefaultViewName)
+		fieldIds := make([]string, 0, len(t.fields))
+		for _, f := range t.fields {
+			fieldIds = append(fieldIds, f.Id)
+		}
+		writeData(w, map[string]interface{}{"table_id": id, "default_view_id": t.views[0].Id, "field_id_list": fieldIds})
+
+	case r.Method == http.MethodPost && action == "batch_create":
+		var req struct {
+			Tables []struct {
+				Name string `json:"name"`
+			} `json:"tables"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || len(req.Tables) == 0 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "tables is required")
+			return
+		}
+		for _, t := range req.Tables {
+			if findTableByName(a, t.Name) != "" {
+				writeError(w, http.StatusOK, CodeTableNameDuplicated, "TableNameDuplicated: "+t.Name)
+				return
+			}
+		}
+		ids := make([]string, 0, len(req.Tables))
+		for _, t := range req.Tables {
+			id, _ := s.createTable(a, t.Name, nil, "")
+			ids = append(ids, id)
+		}
+		writeData(w, map[string]interface{}{"table_ids": ids})
+
+	case r.Method == http.MethodPost && action == "batch_delete":
+		var req struct {
+			TableIds []string `json:"table_ids"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || len(req.TableIds) == 0 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "table_ids is required")
+			return
+		}
+		for _, id := range req.TableIds {
+			if _, ok := a.tables[id]; !ok {
+				writeError(w, http.StatusOK, CodeTableNotFound, "TableIdNotFound: "+id)
+				return
+			}
+		}
+		for _, id := range req.TableIds {
+			removeTable(a, id)
+		}
+		writeData(w, map[string]interface{}{})
+
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// serveTable 处理重命名、删除数据表
+func (s *Server) serveTable(w http.ResponseWriter, r *http.Request, a *app, tableId string, t *table, body []byte) {
+	switch r.Method {
+	case http.MethodPatch:
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || req.Name == "" {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "name is required")
+			return
+		}
+		if id := findTableByName(a, req.Name); id != "" && id != tableId {
+			writeError(w, http.StatusOK, CodeTableNameDuplicated, "TableNameDuplicated")
+			return
+		}
+		t.name = req.Name
+		writeData(w, map[string]interface{}{"name": t.name})
+	case http.MethodDelete:
+		removeTable(a, tableId)
+		writeData(w, map[string]interface{}{})
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// createTable 新增数据表，没有字段时使用默认的索引字段，并新增默认视图
+func (s *Server) createTable(a *app, name string, fields []*Field, viewName string) (string, *table) {
+	id := s.newId("tbl", 13)
+	t := &table{name: name}
+	a.tables[id] = t
+	a.order = append(a.order, id)
+
+	if len(fields) == 0 {
+		fields = []*Field{{Name: defaultFieldName, Type: fieldTypeText}}
+	}
+	for i, f := range fields {
+		f.IsPrimary = i == 0
+		s.addField(t, *f)
+	}
+	if viewName == "" {
+		viewName = defaultViewName
+	}
+	s.addView(t, View{Name: viewName})
+	return id, t
+}
+
+func findTableByName(a *app, name string) string {
+	for _, id := range a.order {
+		if a.tables[id].name == name {
+			return id
+		}
+	}
+	return ""
+}
+
+func removeTable(a *app, tableId string) {
+	delete(a.tables, tableId)
+	for i, id := range a.order {
+		if id == tableId {
+			a.order = append(a.order[:i], a.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// serveFields 处理字段接口，rest 为 fields 之后的路径
+func (s *Server) serveFields(w http.ResponseWriter, r *http.Request, t *table, rest []string, body []byte) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			start, end, ok := pageRange(w, r, len(t.fields), DefaultPageSize, MaxMetaPageSize)
+			if !ok {
+				return
+			}
+			items := make([]Field, 0, end-start)
+			for _, f := range t.fields[start:end] {
+				items = append(items, copyField(f))
+			}
+			writeData(w, pageData(items, end, len(t.fields)))
+		case http.MethodPost:
+			var req Field
+			if err := json.Unmarshal(body, &req); err != nil || req.Name == "" || req.Type == 0 {
+				writeError(w, http.StatusBadRequest, CodeInvalidParam, "field_name and type are required")
+				return
+			}
+			if findFieldByName(t, req.Name) != nil {
+				writeError(w, http.StatusOK, CodeFieldNameDuplicated, "FieldNameDuplicated")
+				return
+			}
+			req.Id, req.IsPrimary = "", len(t.fields) == 0
+			writeData(w, map[string]interface{}{"field": copyField(s.addField(t, req))})
+		default:
+			writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+		}
+		return
+	}
+
+	field := findField(t, rest[0])
+	if field == nil {
+		writeError(w, http.StatusOK, CodeFieldNotFound, "FieldIdNotFound")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var req Field
+		if err := json.Unmarshal(body, &req); err != nil || req.Name == "" || req.Type == 0 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "field_name and type are required")
+			return
+		}
+		if other := findFieldByName(t, req.Name); other != nil && other != field {
+			writeError(w, http.StatusOK, CodeFieldNameDuplicated, "FieldNameDuplicated")
+			return
+		}
+		// 记录按字段名称保存，重命名字段时同步修改记录
+		if req.Name != field.Name {
+			for _, rec := range t.records {
+				if v, ok := rec.Fields[field.Name]; ok {
+					delete(rec.Fields, field.Name)
+					rec.Fields[req.Name] = v
+				}
+			}
+		}
+		req.Id, req.IsPrimary = field.Id, field.IsPrimary
+		s.assignOptionIds(&req)
+		*field = req
+		writeData(w, map[string]interface{}{"field": copyField(field)})
+	case http.MethodDelete:
+		if field.IsPrimary {
+			writeError(w, http.StatusOK, CodeInvalidParam, "primary field can not be deleted")
+			return
+		}
+		for i, f := range t.fields {
+			if f == field {
+				t.fields = append(t.fields[:i], t.fields[i+1:]...)
+				break
+			}
+		}
+		for _, rec := range t.records {
+			delete(rec.Fields, field.Name)
+		}
+		writeData(w, map[string]interface{}{"field_id": field.Id, "deleted": true})
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// addField 新增字段，调用方需要持有锁
+func (s *Server) addField(t *table, field Field) *Field {
+	f := copyField(&field)
+	if f.Id == "" {
+		f.Id = s.newId("fld", 7)
+	}
+	s.assignOptionIds(&f)
+	t.fields = append(t.fields, &f)
+	return &f
+}
+
+// assignOptionIds 为没有 id 的单选、多选选项生成 id，调用方需要持有锁
+func (s *Server) assignOptionIds(f *Field) {
+	options, _ := f.Property["options"].([]interface{})
+	for _, o := range options {
+		if option, ok := o.(map[string]interface{}); ok {
+			if id, _ := option["id"].(string); id == "" {
+				option["id"] = s.newId("opt", 10)
+			}
+		}
+	}
+}
+
+func findField(t *table, fieldId string) *Field {
+	for _, f := range t.fields {
+		if f.Id == fieldId {
+			return f
+		}
+	}
+	return nil
+}
+
+func findFieldByName(t *table, name string) *Field {
+	for _, f := range t.fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// copyField 通过 JSON 编解码复制字段，使 Property 中的值与接口返回的类型一致
+func copyField(f *Field) Field {
+	var result Field
+	if err := clone(f, &result); err != nil {
+		return *f
+	}
+	return result
+}
+
+// serveViews 处理视图接口，rest 为 views 之后的路径
+func (s *Server) serveViews(w http.ResponseWriter, r *http.Request, t *table, rest []string, body []byte) {
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			start, end, ok := pageRange(w, r, len(t.views), DefaultPageSize, MaxMetaPageSize)
+			if !ok {
+				return
+			}
+			items := make([]View, 0, end-start)
+			for _, v := range t.views[start:end] {
+				items = append(items, copyView(v))
+			}
+			writeData(w, pageData(items, end, len(t.views)))
+		case http.MethodPost:
+			var req View
+			if err := json.Unmarshal(body, &req); err != nil || req.Name == "" {
+				writeError(w, http.StatusBadRequest, CodeInvalidParam, "view_name is required")
+				return
+			}
+			writeData(w, map[string]interface{}{"view": copyView(s.addView(t, View{Name: req.Name, Type: req.Type}))})
+		default:
+			writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+		}
+		return
+	}
+
+	index := -1
+	for i, v := range t.views {
+		if v.Id == rest[0] {
+			index = i
+		}
+	}
+	if index < 0 {
+		writeError(w, http.StatusOK, CodeViewNotFound, "ViewIdNotFound")
+		return
+	}
+	view := t.views[index]
+	switch r.Method {
+	case http.MethodGet:
+		writeData(w, map[string]interface{}{"view": copyView(view)})
+	case http.MethodPatch:
+		var req struct {
+			Name     string                 `json:"view_name"`
+			Property map[string]interface{} `json:"property"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+			return
+		}
+		if req.Name != "" {
+			view.Name = req.Name
+		}
+		if req.Property != nil {
+			view.Property = req.Property
+		}
+		writeData(w, map[string]interface{}{"view": copyView(view)})
+	case http.MethodDelete:
+		t.views = append(t.views[:index], t.views[index+1:]...)
+		writeData(w, map[string]interface{}{})
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// addView 新增视图，没有类型时为表格视图，调用方需要持有锁
+func (s *Server) addView(t *table, view View) *View {
+	v := copyView(&view)
+	if v.Id == "" {
+		v.Id = s.newId("vew", 7)
+	}
+	if v.Type == "" {
+		v.Type = "grid"
+	}
+	t.views = append(t.views, &v)
+	return &v
+}
+
+// copyView 通过 JSON 编解码复制视图
+func copyView(v *View) View {
+	var result View
+	if err := clone(v, &result); err != nil {
+		return *v
+	}
+	return result
+}
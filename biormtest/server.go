@@ -0,0 +1,874 @@
+// Package biormtest 提供模拟多维表格开放接口的测试服务器，数据保存在内存中，
+// 用于在没有飞书租户的环境（例如 CI）中测试使用 biorm 或飞书 SDK 的代码。
+//
+// 支持的接口：
+//
+//	获取 tenant_access_token、app_access_token
+//	获取知识空间节点信息（wiki get_node）
+//	获取多维表格元数据
+//	列出、新增、重命名、删除数据表，批量新增、删除数据表
+//	列出、新增、更新、删除字段
+//	列出、新增、获取、更新、删除视图
+//	查询、新增、更新、删除记录，批量获取、新增、更新、删除记录
+//
+// Usage:
+//
+//	srv := biormtest.NewServer()
+//	defer srv.Close()
+//	srv.AddTable("app", "tbl0xe5g8PP3U3cS", "任务")
+//	srv.Insert("app", "tbl0xe5g8PP3U3cS", map[string]interface{}{"名称": "写文档", "进度": 0.5})
+//	db := biorm.NewDB(srv.Client())
+package biormtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+)
+
+const (
+	// AppId、AppSecret Client 使用的应用凭证，服务器不校验凭证
+	AppId     = "cli_biormtest"
+	AppSecret = "biormtest"
+
+	// TenantAccessToken 服务器下发的 tenant_access_token，其它接口要求请求头携带该 token
+	TenantAccessToken = "t-biormtest"
+
+	// DefaultPageSize 查询记录时未指定 page_size 的默认值
+	DefaultPageSize = 20
+	// MaxPageSize 查询记录时 page_size 的最大值
+	MaxPageSize = 500
+	// MaxMetaPageSize 列出数据表、字段、视图时 page_size 的最大值
+	MaxMetaPageSize = 100
+)
+
+// 与开放平台一致的错误码
+const (
+	CodeInvalidToken        = 99991663 // tenant_access_token 无效
+	CodeInvalidParam        = 1254000  // 请求参数错误
+	CodeTableNameDuplicated = 1254013  // 数据表名称重复
+	CodeFieldNameDuplicated = 1254014  // 字段名称重复
+	CodeInvalidFilter       = 1254018  // 筛选条件错误
+	CodeAppNotFound         = 1254040  // 多维表格不存在
+	CodeTableNotFound       = 1254041  // 数据表不存在
+	CodeViewNotFound        = 1254042  // 视图不存在
+	CodeRecordNotFound      = 1254043  // 记录不存在
+	CodeFieldNotFound       = 1254044  // 字段不存在
+	CodeFieldNameNotFound   = 1254045  // 记录中的字段名称不存在
+	CodeWikiNodeNotFound    = 131005   // 知识空间节点不存在
+)
+
+// Record 内存中的一条记录
+type Record struct {
+	Id     string                 `json:"record_id"`
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// Table 数据表的 ID 与名称
+type Table struct {
+	Id   string `json:"table_id"`
+	Name string `json:"name"`
+}
+
+// Field 数据表中的字段，Property 与接口中字段的 property 一致
+type Field struct {
+	Id          string                 `json:"field_id"`
+	Name        string                 `json:"field_name"`
+	Type        int                    `json:"type"`
+	UiType      string                 `json:"ui_type,omitempty"`
+	IsPrimary   bool                   `json:"is_primary"`
+	Property    map[string]interface{} `json:"property,omitempty"`
+	Description interface{}            `json:"description,omitempty"`
+}
+
+// View 数据表中的视图，Property 与接口中视图的 property 一致
+type View struct {
+	Id       string                 `json:"view_id"`
+	Name     string                 `json:"view_name"`
+	Type     string                 `json:"view_type"`
+	Property map[string]interface{} `json:"property,omitempty"`
+}
+
+// Request 服务器收到的一次请求，用于断言
+type Request struct {
+	Method string
+	Host   string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+type table struct {
+	name    string
+	fields  []*Field
+	views   []*View
+	records []*Record
+}
+
+type app struct {
+	name   string
+	tables map[string]*table
+	order  []string // 数据表 ID，按新增顺序
+}
+
+// DefaultLocation 文档的默认时区 UTC+8，与 biorm.DefaultLocation 一致
+var DefaultLocation = time.FixedZone("UTC+8", 8*60*60)
+
+// Server 模拟多维表格开放接口的测试服务器
+//
+// 数据表设置了字段时，新增、更新记录以及查询记录的筛选、排序与返回字段会检查字段名称是否存在；
+// 没有字段的数据表（例如只通过 AddTable 新增）不做检查。
+// 通过接口新增的数据表与开放接口一致，会带有索引字段与默认视图。
+type Server struct {
+	*httptest.Server
+
+	// Location 计算日期条件使用的文档时区，默认为 DefaultLocation
+	Location *time.Location
+	// Now 返回当前时间，用于计算 Today 等相对日期，默认为 time.Now
+	Now func() time.Time
+
+	mu           sync.Mutex
+	apps         map[string]*app
+	nodes        map[string]string   // 知识空间节点 token -> 多维表格 app_token
+	clientTokens map[string][]string // client_token -> 新增的记录 ID
+	requests     []Request
+	seq          int
+}
+
+// NewServer 启动测试服务器，使用完毕后需要调用 Close
+func NewServer() *Server {
+	s := &Server{
+		Location:     DefaultLocation,
+		Now:          time.Now,
+		apps:         make(map[string]*app),
+		nodes:        make(map[string]string),
+		clientTokens: make(map[string][]string),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Client 返回请求测试服务器的飞书 SDK 客户端
+func (s *Server) Client(options ...lark.ClientOptionFunc) *lark.Client {
+	options = append([]lark.ClientOptionFunc{lark.WithOpenBaseUrl(s.URL)}, options...)
+	return lark.NewClient(AppId, AppSecret, options...)
+}
+
+// AddApp 新增多维表格，已存在时只修改名称
+func (s *Server) AddApp(appToken, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.app(appToken).name = name
+}
+
+// AddTable 新增数据表，多维表格不存在时自动新增，数据表已存在时只修改名称
+func (s *Server) AddTable(appToken, tableId, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.table(appToken, tableId).name = name
+}
+
+// AddField 向数据表新增字段，数据表不存在时自动新增，返回字段 ID
+// field.Id 为空时自动生成，单选、多选字段的选项没有 id 时也会自动生成
+func (s *Server) AddField(appToken, tableId string, field Field) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addField(s.table(appToken, tableId), field).Id
+}
+
+// AddView 向数据表新增视图，数据表不存在时自动新增，返回视图 ID
+func (s *Server) AddView(appToken, tableId string, view View) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addView(s.table(appToken, tableId), view).Id
+}
+
+// AddWikiNode 新增指向多维表格的知识空间节点
+func (s *Server) AddWikiNode(nodeToken, appToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[nodeToken] = appToken
+}
+
+// Insert 向数据表写入记录，数据表不存在时自动新增，返回新记录的 ID
+func (s *Server) Insert(appToken, tableId string, fields ...map[string]interface{}) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.table(appToken, tableId)
+	ids := make([]string, 0, len(fields))
+	for _, f := range fields {
+		ids = append(ids, s.insert(t, normalizeFields(f)).Id)
+	}
+	return ids
+}
+
+// Tables 返回多维表格中的全部数据表，按新增顺序排列
+func (s *Server) Tables(appToken string) []Table {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.apps[appToken]
+	if !ok {
+		return nil
+	}
+	result := make([]Table, 0, len(a.order))
+	for _, id := range a.order {
+		result = append(result, Table{Id: id, Name: a.tables[id].name})
+	}
+	return result
+}
+
+// Fields 返回数据表中的全部字段
+func (s *Server) Fields(appToken, tableId string) []Field {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookupTable(appToken, tableId)
+	if t == nil {
+		return nil
+	}
+	result := make([]Field, 0, len(t.fields))
+	for _, f := range t.fields {
+		result = append(result, copyField(f))
+	}
+	return result
+}
+
+// Views 返回数据表中的全部视图
+func (s *Server) Views(appToken, tableId string) []View {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookupTable(appToken, tableId)
+	if t == nil {
+		return nil
+	}
+	result := make([]View, 0, len(t.views))
+	for _, v := range t.views {
+		result = append(result, copyView(v))
+	}
+	return result
+}
+
+// Records 返回数据表中的全部记录
+func (s *Server) Records(appToken, tableId string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.lookupTable(appToken, tableId)
+	if t == nil {
+		return nil
+	}
+	result := make([]Record, 0, len(t.records))
+	for _, r := range t.records {
+		result = append(result, copyRecord(r))
+	}
+	return result
+}
+
+// Requests 返回服务器收到的全部请求，不包括获取 access_token 的请求
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// ResetRequests 清空已记录的请求
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+// app 返回多维表格，不存在时新增，调用方需要持有锁
+func (s *Server) app(appToken string) *app {
+	a, ok := s.apps[appToken]
+	if !ok {
+		a = &app{name: appToken, tables: make(map[string]*table)}
+		s.apps[appToken] = a
+	}
+	return a
+}
+
+// table 返回数据表，不存在时新增，调用方需要持有锁
+func (s *Server) table(appToken, tableId string) *table {
+	a := s.app(appToken)
+	t, ok := a.tables[tableId]
+	if !ok {
+		t = &table{name: tableId}
+		a.tables[tableId] = t
+		a.order = append(a.order, tableId)
+	}
+	return t
+}
+
+// lookupTable 返回数据表，不存在时返回 nil，调用方需要持有锁
+func (s *Server) lookupTable(appToken, tableId string) *table {
+	a, ok := s.apps[appToken]
+	if !ok {
+		return nil
+	}
+	return a.tables[tableId]
+}
+
+// newId 生成 prefix 开头、总长度与开放接口一致的 ID，调用方需要持有锁
+func (s *Server) newId(prefix string, width int) string {
+	s.seq++
+	return fmt.Sprintf("%s%0*d", prefix, width, s.seq)
+}
+
+func (s *Server) insert(t *table, fields map[string]interface{}) *Record {
+	r := &Record{Id: s.newId("rec", 8), Fields: fields}
+	t.records = append(t.records, r)
+	return r
+}
+
+// ServeHTTP 处理开放接口请求
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	path := r.URL.Path
+
+	switch path {
+	case "/open-apis/auth/v3/tenant_access_token/internal":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"code": 0, "msg": "ok", "tenant_access_token": TenantAccessToken, "expire": 7200})
+		return
+	case "/open-apis/auth/v3/app_access_token/internal":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"code": 0, "msg": "ok", "app_access_token": "a-biormtest", "expire": 7200})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, Request{Method: r.Method, Host: r.Host, Path: path, Query: r.URL.RawQuery, Body: body})
+
+	if r.Header.Get("Authorization") != "Bearer "+TenantAccessToken {
+		writeError(w, http.StatusUnauthorized, CodeInvalidToken, "Invalid access token for authorization")
+		return
+	}
+
+	if path == "/open-apis/wiki/v2/spaces/get_node" {
+		s.getNode(w, r)
+		return
+	}
+
+	const appsPrefix = "/open-apis/bitable/v1/apps/"
+	if !strings.HasPrefix(path, appsPrefix) {
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(path, appsPrefix), "/")
+	a, ok := s.apps[parts[0]]
+	if !ok {
+		writeError(w, http.StatusOK, CodeAppNotFound, "BaseTokenNotFound")
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		s.getApp(w, parts[0], a)
+		return
+	case len(parts) < 2 || parts[1] != "tables":
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+		return
+	case len(parts) == 2 || len(parts) == 3 && r.Method == http.MethodPost:
+		s.serveTables(w, r, a, parts[2:], body)
+		return
+	}
+
+	t, ok := a.tables[parts[2]]
+	if !ok {
+		writeError(w, http.StatusOK, CodeTableNotFound, "TableIdNotFound")
+		return
+	}
+	if len(parts) == 3 {
+		s.serveTable(w, r, a, parts[2], t, body)
+		return
+	}
+	switch parts[3] {
+	case "records":
+		s.serveRecords(w, r, t, parts[4:], body)
+	case "fields":
+		s.serveFields(w, r, t, parts[4:], body)
+	case "views":
+		s.serveViews(w, r, t, parts[4:], body)
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// serveRecords 处理记录接口，rest 为 records 之后的路径
+func (s *Server) serveRecords(w http.ResponseWriter, r *http.Request, t *table, rest []string, body []byte) {
+	action := ""
+	if len(rest) > 0 {
+		action = rest[0]
+	}
+	switch {
+	case r.Method == http.MethodPost && action == "search":
+		s.search(w, r, t, body)
+	case r.Method == http.MethodPost && action == "":
+		s.create(w, r, t, body)
+	case r.Method == http.MethodPost && action == "batch_get":
+		s.batchGet(w, t, body)
+	case r.Method == http.MethodPost && action == "batch_create":
+		s.batchCreate(w, r, t, body)
+	case r.Method == http.MethodPost && action == "batch_update":
+		s.batchUpdate(w, t, body)
+	case r.Method == http.MethodPost && action == "batch_delete":
+		s.batchDelete(w, t, body)
+	case r.Method == http.MethodPut && action != "":
+		s.update(w, t, action, body)
+	case r.Method == http.MethodDelete && action != "":
+		s.delete(w, t, action)
+	default:
+		writeError(w, http.StatusNotFound, http.StatusNotFound, "404 page not found")
+	}
+}
+
+func (s *Server) getNode(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	appToken, ok := s.nodes[token]
+	if !ok {
+		writeError(w, http.StatusOK, CodeWikiNodeNotFound, "node not found")
+		return
+	}
+	title := appToken
+	if a, ok := s.apps[appToken]; ok {
+		title = a.name
+	}
+	writeData(w, map[string]interface{}{"node": map[string]interface{}{
+		"node_token": token,
+		"obj_token":  appToken,
+		"obj_type":   "bitable",
+		"node_type":  "origin",
+		"title":      title,
+	}})
+}
+
+func (s *Server) getApp(w http.ResponseWriter, appToken string, a *app) {
+	writeData(w, map[string]interface{}{"app": map[string]interface{}{
+		"app_token":   appToken,
+		"name":        a.name,
+		"revision":    1,
+		"is_advanced": false,
+		"time_zone":   s.Location.String(),
+	}})
+}
+
+type sortInfo struct {
+	FieldName string `json:"field_name"`
+	Desc      bool   `json:"desc"`
+}
+
+type searchBody struct {
+	ViewId     string      `json:"view_id"`
+	FieldNames []string    `json:"field_names"`
+	Sort       []sortInfo  `json:"sort"`
+	Filter     *filterInfo `json:"filter"`
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, t *table, body []byte) {
+	var req searchBody
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+			return
+		}
+	}
+
+	if !checkSearchFields(w, t, req) {
+		return
+	}
+
+	e := &evaluator{now: s.Now().In(s.Location), loc: s.Location}
+	matched := make([]*Record, 0, len(t.records))
+	for _, rec := range t.records {
+		ok, err := e.match(req.Filter, rec.Fields)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeInvalidFilter, err.Error())
+			return
+		}
+		if ok {
+			matched = append(matched, rec)
+		}
+	}
+	sortRecords(matched, req.Sort)
+
+	start, end, ok := pageRange(w, r, len(matched), DefaultPageSize, MaxPageSize)
+	if !ok {
+		return
+	}
+	items := make([]Record, 0, end-start)
+	for _, rec := range matched[start:end] {
+		items = append(items, projectRecord(rec, req.FieldNames))
+	}
+	writeData(w, pageData(items, end, len(matched)))
+}
+
+type recordBody struct {
+	RecordId string                 `json:"record_id"`
+	Fields   map[string]interface{} `json:"fields"`
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, t *table, body []byte) {
+	var req recordBody
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	if !checkFieldNames(w, t, req.Fields) {
+		return
+	}
+	records := s.createRecords(t, r.URL.Query().Get("client_token"), []recordBody{req})
+	writeData(w, map[string]interface{}{"record": records[0]})
+}
+
+func (s *Server) batchCreate(w http.ResponseWriter, r *http.Request, t *table, body []byte) {
+	var req struct {
+		Records []recordBody `json:"records"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	// 任意一条记录的字段不存在时整批失败
+	for _, b := range req.Records {
+		if !checkFieldNames(w, t, b.Fields) {
+			return
+		}
+	}
+	writeData(w, map[string]interface{}{"records": s.createRecords(t, r.URL.Query().Get("client_token"), req.Records)})
+}
+
+// createRecords 新增记录，相同的 client_token 返回第一次新增的记录
+func (s *Server) createRecords(t *table, clientToken string, bodies []recordBody) []Record {
+	if ids, ok := s.clientTokens[clientToken]; ok && clientToken != "" {
+		records := make([]Record, 0, len(ids))
+		for _, id := range ids {
+			if rec := findRecord(t, id); rec != nil {
+				records = append(records, copyRecord(rec))
+			}
+		}
+		return records
+	}
+
+	records := make([]Record, 0, len(bodies))
+	ids := make([]string, 0, len(bodies))
+	for _, b := range bodies {
+		rec := s.insert(t, normalizeFields(b.Fields))
+		records = append(records, copyRecord(rec))
+		ids = append(ids, rec.Id)
+	}
+	if clientToken != "" {
+		s.clientTokens[clientToken] = ids
+	}
+	return records
+}
+
+func (s *Server) update(w http.ResponseWriter, t *table, recordId string, body []byte) {
+	var req recordBody
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	rec := findRecord(t, recordId)
+	if rec == nil {
+		writeError(w, http.StatusOK, CodeRecordNotFound, "RecordIdNotFound")
+		return
+	}
+	if !checkFieldNames(w, t, req.Fields) {
+		return
+	}
+	updateFields(rec, req.Fields)
+	writeData(w, map[string]interface{}{"record": copyRecord(rec)})
+}
+
+func (s *Server) batchUpdate(w http.ResponseWriter, t *table, body []byte) {
+	var req struct {
+		Records []recordBody `json:"records"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	// 任意一条记录不存在时整批失败
+	for _, b := range req.Records {
+		if findRecord(t, b.RecordId) == nil {
+			writeError(w, http.StatusOK, CodeRecordNotFound, "RecordIdNotFound: "+b.RecordId)
+			return
+		}
+		if !checkFieldNames(w, t, b.Fields) {
+			return
+		}
+	}
+	records := make([]Record, 0, len(req.Records))
+	for _, b := range req.Records {
+		rec := findRecord(t, b.RecordId)
+		updateFields(rec, b.Fields)
+		records = append(records, copyRecord(rec))
+	}
+	writeData(w, map[string]interface{}{"records": records})
+}
+
+func (s *Server) delete(w http.ResponseWriter, t *table, recordId string) {
+	if !removeRecord(t, recordId) {
+		writeError(w, http.StatusOK, CodeRecordNotFound, "RecordIdNotFound")
+		return
+	}
+	writeData(w, map[string]interface{}{"deleted": true, "record_id": recordId})
+}
+
+func (s *Server) batchDelete(w http.ResponseWriter, t *table, body []byte) {
+	var req struct {
+		Records []string `json:"records"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	for _, id := range req.Records {
+		if findRecord(t, id) == nil {
+			writeError(w, http.StatusOK, CodeRecordNotFound, "RecordIdNotFound: "+id)
+			return
+		}
+	}
+	records := make([]map[string]interface{}, 0, len(req.Records))
+	for _, id := range req.Records {
+		removeRecord(t, id)
+		records = append(records, map[string]interface{}{"deleted": true, "record_id": id})
+	}
+	writeData(w, map[string]interface{}{"records": records})
+}
+
+func (s *Server) batchGet(w http.ResponseWriter, t *table, body []byte) {
+	var req struct {
+		RecordIds []string `json:"record_ids"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidParam, err.Error())
+		return
+	}
+	records := make([]Record, 0, len(req.RecordIds))
+	absent := make([]string, 0)
+	for _, id := range req.RecordIds {
+		if rec := findRecord(t, id); rec != nil {
+			records = append(records, copyRecord(rec))
+		} else {
+			absent = append(absent, id)
+		}
+	}
+	writeData(w, map[string]interface{}{"records": records, "absent_record_ids": absent})
+}
+
+// checkFieldNames 数据表设置了字段时检查记录中的字段名称，不存在时写入错误并返回 false
+func checkFieldNames(w http.ResponseWriter, t *table, fields map[string]interface{}) bool {
+	if len(t.fields) == 0 {
+		return true
+	}
+	for name := range fields {
+		if findFieldByName(t, name) == nil {
+			writeError(w, http.StatusOK, CodeFieldNameNotFound, "FieldNameNotFound: "+name)
+			return false
+		}
+	}
+	return true
+}
+
+// checkSearchFields 检查查询记录的返回字段、排序与筛选条件中的字段是否存在，不存在时写入错误并返回 false
+func checkSearchFields(w http.ResponseWriter, t *table, req searchBody) bool {
+	if len(t.fields) == 0 {
+		return true
+	}
+	names := append([]string{}, req.FieldNames...)
+	for _, s := range req.Sort {
+		names = append(names, s.FieldName)
+	}
+	for _, name := range names {
+		if findFieldByName(t, name) == nil {
+			writeError(w, http.StatusOK, CodeFieldNameNotFound, "FieldNameNotFound: "+name)
+			return false
+		}
+	}
+	if name := unknownFilterField(t, req.Filter); name != "" {
+		writeError(w, http.StatusBadRequest, CodeInvalidFilter, "InvalidFilter: field not found: "+name)
+		return false
+	}
+	return true
+}
+
+// unknownFilterField 返回筛选条件中第一个不存在的字段名称，全部存在时返回空字符串
+func unknownFilterField(t *table, f *filterInfo) string {
+	if f == nil {
+		return ""
+	}
+	for _, c := range f.Conditions {
+		if findFieldByName(t, c.FieldName) == nil {
+			return c.FieldName
+		}
+	}
+	for _, child := range f.Children {
+		if name := unknownFilterField(t, child); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func findRecord(t *table, recordId string) *Record {
+	for _, rec := range t.records {
+		if rec.Id == recordId {
+			return rec
+		}
+	}
+	return nil
+}
+
+func removeRecord(t *table, recordId string) bool {
+	for i, rec := range t.records {
+		if rec.Id == recordId {
+			t.records = append(t.records[:i], t.records[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// updateFields 更新记录的字段，值为 nil 时清空字段
+func updateFields(rec *Record, fields map[string]interface{}) {
+	for name, value := range normalizeFields(fields) {
+		if value == nil {
+			delete(rec.Fields, name)
+			continue
+		}
+		rec.Fields[name] = value
+	}
+}
+
+// normalizeFields 通过 JSON 编解码复制字段，使内存中的值与接口返回的类型一致
+func normalizeFields(fields map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	if len(fields) == 0 {
+		return result
+	}
+	if err := clone(fields, &result); err != nil {
+		for k, v := range fields {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// clone 通过 JSON 编解码将 src 复制到 dst
+func clone(src, dst interface{}) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+func copyRecord(rec *Record) Record {
+	return Record{Id: rec.Id, Fields: normalizeFields(rec.Fields)}
+}
+
+// projectRecord 只保留 fieldNames 中的字段，fieldNames 为空时返回全部字段
+func projectRecord(rec *Record, fieldNames []string) Record {
+	result := copyRecord(rec)
+	if len(fieldNames) == 0 {
+		return result
+	}
+	projected := make(map[string]interface{}, len(fieldNames))
+	for _, name := range fieldNames {
+		if v, ok := result.Fields[name]; ok {
+			projected[name] = v
+		}
+	}
+	result.Fields = projected
+	return result
+}
+
+// sortRecords 按排序条件稳定排序，空值排在最后
+func sortRecords(records []*Record, sorts []sortInfo) {
+	if len(sorts) == 0 {
+		return
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, s := range sorts {
+			a, b := records[i].Fields[s.FieldName], records[j].Fields[s.FieldName]
+			if isEmpty(a) || isEmpty(b) {
+				if isEmpty(a) != isEmpty(b) {
+					return isEmpty(b)
+				}
+				continue
+			}
+			c := compareCells(a, b)
+			if c == 0 {
+				continue
+			}
+			if s.Desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// pageRange 按 page_size 与 page_token（下一条数据的下标）返回本页数据的范围，参数不合法时写入错误并返回 false
+func pageRange(w http.ResponseWriter, r *http.Request, n, defaultSize, maxSize int) (start, end int, ok bool) {
+	size := defaultSize
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		size, _ = strconv.Atoi(v)
+		if size <= 0 || size > maxSize {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "invalid page_size: "+v)
+			return 0, 0, false
+		}
+	}
+	if v := r.URL.Query().Get("page_token"); v != "" {
+		var err error
+		start, err = strconv.Atoi(v)
+		if err != nil || start < 0 {
+			writeError(w, http.StatusBadRequest, CodeInvalidParam, "invalid page_token: "+v)
+			return 0, 0, false
+		}
+	}
+	if start > n {
+		start = n
+	}
+	end = start + size
+	if end > n {
+		end = n
+	}
+	return start, end, true
+}
+
+// pageData 返回分页接口的 data
+func pageData(items interface{}, end, total int) map[string]interface{} {
+	hasMore := end < total
+	pageToken := ""
+	if hasMore {
+		pageToken = strconv.Itoa(end)
+	}
+	return map[string]interface{}{"items": items, "has_more": hasMore, "page_token": pageToken, "total": total}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	_ = json.NewEncoder(&buf).Encode(v)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"code": 0, "msg": "success", "data": data})
+}
+
+func writeError(w http.ResponseWriter, status, code int, msg string) {
+	writeJSON(w, status, map[string]interface{}{"code": code, "msg": msg})
+}
@@ -0,0 +1,313 @@
+package biormtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+func TestWikiAndApp(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp("app", "项目管理")
+	srv.AddWikiNode("wiki", "app")
+	cli := srv.Client()
+	ctx := context.Background()
+
+	node, err := cli.Wiki.V2.Space.GetNode(ctx, larkwiki.NewGetNodeSpaceReqBuilder().Token("wiki").Build())
+	if err != nil || !node.Success() || *node.Data.Node.ObjToken != "app" || *node.Data.Node.ObjType != "bitable" {
+		t.Fatalf("GetNode() = %+v, %v", node, err)
+	}
+	if node, _ := cli.Wiki.V2.Space.GetNode(ctx, larkwiki.NewGetNodeSpaceReqBuilder().Token("missing").Build()); node.Code != CodeWikiNodeNotFound {
+		t.Errorf("GetNode(missing) code = %d", node.Code)
+	}
+
+	app, err := cli.Bitable.V1.App.Get(ctx, larkbitable.NewGetAppReqBuilder().AppToken("app").Build())
+	if err != nil || !app.Success() || *app.Data.App.Name != "项目管理" {
+		t.Fatalf("App.Get() = %+v, %v", app, err)
+	}
+
+	resp, err := http.Get(srv.URL + "/open-apis/bitable/v1/apps/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("request without token status = %d", resp.StatusCode)
+	}
+}
+
+func TestRecords(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddTable("app", "tbl", "任务")
+	records := larkbitable.NewAppTableRecordBuilder()
+	recordsApi := srv.Client().Bitable.V1.AppTableRecord
+	ctx := context.Background()
+
+	created, err := recordsApi.Create(ctx, larkbitable.NewCreateAppTableRecordReqBuilder().
+		AppToken("app").TableId("tbl").ClientToken("token-1").
+		AppTableRecord(records.Fields(map[string]interface{}{"名称": "写文档", "进度": 0.5}).Build()).
+		Build())
+	if err != nil || !created.Success() {
+		t.Fatalf("Create() = %+v, %v", created, err)
+	}
+	id := *created.Data.Record.RecordId
+
+	// 相同的 client_token 不会重复新增
+	again, _ := recordsApi.Create(ctx, larkbitable.NewCreateAppTableRecordReqBuilder().
+		AppToken("app").TableId("tbl").ClientToken("token-1").
+		AppTableRecord(records.Fields(map[string]interface{}{"名称": "写文档"}).Build()).
+		Build())
+	if *again.Data.Record.RecordId != id || len(srv.Records("app", "tbl")) != 1 {
+		t.Errorf("idempotent Create() = %s, records %d", *again.Data.Record.RecordId, len(srv.Records("app", "tbl")))
+	}
+
+	batch, err := recordsApi.BatchCreate(ctx, larkbitable.NewBatchCreateAppTableRecordReqBuilder().
+		AppToken("app").TableId("tbl").
+		Body(larkbitable.NewBatchCreateAppTableRecordReqBodyBuilder().Records([]*larkbitable.AppTableRecord{
+			records.Fields(map[string]interface{}{"名称": "评审", "进度": 1, "标签": []string{"前端", "后端"}}).Build(),
+			records.Fields(map[string]interface{}{"名称": "发布", "标签": []string{"后端"}}).Build(),
+		}).Build()).
+		Build())
+	if err != nil || !batch.Success() || len(batch.Data.Records) != 2 {
+		t.Fatalf("BatchCreate() = %+v, %v", batch, err)
+	}
+
+	updated, err := recordsApi.Update(ctx, larkbitable.NewUpdateAppTableRecordReqBuilder().
+		AppToken("app").TableId("tbl").RecordId(id).
+		AppTableRecord(records.Fields(map[string]interface{}{"进度": 0.8}).Build()).
+		Build())
+	if err != nil || !updated.Success() || updated.Data.Record.Fields["名称"] != "写文档" || updated.Data.Record.Fields["进度"] != 0.8 {
+		t.Fatalf("Update() = %+v, %v", updated, err)
+	}
+
+	got, err := recordsApi.BatchGet(ctx, larkbitable.NewBatchGetAppTableRecordReqBuilder().
+		AppToken("app").TableId("tbl").
+		Body(larkbitable.NewBatchGetAppTableRecordReqBodyBuilder().RecordIds([]string{id, "recMissing"}).Build()).
+		Build())
+	if err != nil || len(got.Data.Records) != 1 || len(got.Data.AbsentRecordIds) != 1 {
+		t.Fatalf("BatchGet() = %+v, %v", got, err)
+	}
+
+	deleted, err := recordsApi.Delete(ctx, larkbitable.NewDeleteAppTableRecordReqBuilder().AppToken("app").TableId("tbl").RecordId(id).Build())
+	if err != nil || !deleted.Success() || len(srv.Records("app", "tbl")) != 2 {
+		t.Fatalf("Delete() = %+v, %v", deleted, err)
+	}
+	if deleted, _ := recordsApi.Delete(ctx, larkbitable.NewDeleteAppTableRecordReqBuilder().AppToken("app").TableId("tbl").RecordId(id).Build()); deleted.Code != CodeRecordNotFound {
+		t.Errorf("Delete(deleted) code = %d", deleted.Code)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	if srv.Location != DefaultLocation {
+		t.Errorf("default Location = %v, want UTC+8", srv.Location)
+	}
+	srv.Location = time.UTC
+	today := time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC)
+	srv.Now = func() time.Time { return today.Add(10 * time.Hour) }
+	ms := func(days int) int64 { return today.AddDate(0, 0, days).UnixMilli() }
+
+	srv.AddTable("app", "tbl", "任务")
+	for i, f := range []map[string]interface{}{
+		{"名称": "写文档", "进度": 0.5, "标签": []string{"前端"}, "截止日期": ms(0)},
+		{"名称": "评审文档", "进度": 1, "标签": []string{"前端", "后端"}, "截止日期": ms(1)},
+		{"名称": "发布", "标签": []string{"后端"}, "截止日期": ms(-3)},
+		{"名称": "复盘", "进度": 0.2},
+	} {
+		f["序号"] = i
+		srv.Insert("app", "tbl", f)
+	}
+
+	search := func(body string, query string) (map[string]interface{}, []string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/open-apis/bitable/v1/apps/app/tables/tbl/records/search?"+query, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+TenantAccessToken)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var raw map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		if data, ok := raw["data"].(map[string]interface{}); ok {
+			for _, item := range data["items"].([]interface{}) {
+				fields := item.(map[string]interface{})["fields"].(map[string]interface{})
+				names = append(names, fields["名称"].(string))
+			}
+		}
+		return raw, names
+	}
+	assertNames := func(got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("names = %v, want %v", got, want)
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("names = %v, want %v", got, want)
+			}
+		}
+	}
+
+	_, names := search(`{"filter":{"conjunction":"and","conditions":[
+		{"field_name":"名称","operator":"contains","value":["文档"]},
+		{"field_name":"进度","operator":"isGreaterEqual","value":["0.5"]}]}}`, "")
+	assertNames(names, "写文档", "评审文档")
+
+	_, names = search(`{"filter":{"conjunction":"or","conditions":[
+		{"field_name":"标签","operator":"is","value":["后端"]},
+		{"field_name":"进度","operator":"isEmpty","value":[]}],
+		"children":[{"conjunction":"and","conditions":[
+			{"field_name":"截止日期","operator":"is","value":["Today"]}]}]}}`, "")
+	assertNames(names, "写文档", "发布")
+
+	_, names = search(`{"filter":{"conjunction":"and","conditions":[
+		{"field_name":"截止日期","operator":"isGreater","value":["ExactDate","`+strconv.FormatInt(ms(-1), 10)+`"]}]},
+		"sort":[{"field_name":"截止日期","desc":true}]}`, "")
+	assertNames(names, "评审文档", "写文档")
+
+	_, names = search(`{"filter":{"conjunction":"and","conditions":[
+		{"field_name":"截止日期","operator":"is","value":["TheLastWeek"]},
+		{"field_name":"标签","operator":"contains","value":["后端","测试"]}]}}`, "")
+	assertNames(names, "发布")
+
+	// 分页
+	raw, names := search(`{"sort":[{"field_name":"序号","desc":true}],"field_names":["名称"]}`, "page_size=3")
+	assertNames(names, "复盘", "发布", "评审文档")
+	data := raw["data"].(map[string]interface{})
+	if data["has_more"] != true || data["total"].(float64) != 4 {
+		t.Fatalf("first page = %v", data)
+	}
+	if fields := data["items"].([]interface{})[0].(map[string]interface{})["fields"].(map[string]interface{}); len(fields) != 1 {
+		t.Errorf("projected fields = %v", fields)
+	}
+	raw, names = search(`{"sort":[{"field_name":"序号","desc":true}]}`, "page_size=3&page_token="+data["page_token"].(string))
+	assertNames(names, "写文档")
+	if raw["data"].(map[string]interface{})["has_more"] != false {
+		t.Errorf("last page = %v", raw)
+	}
+
+	// 开放接口不支持的运算符
+	raw, _ = search(`{"filter":{"conjunction":"and","conditions":[{"field_name":"名称","operator":"in","value":["发布"]}]}}`, "")
+	if raw["code"].(float64) != CodeInvalidFilter {
+		t.Errorf("unsupported operator response = %v", raw)
+	}
+
+	// 数据表设置了字段后，返回字段、排序与筛选条件中不存在的字段返回错误
+	srv.AddField("app", "tbl", Field{Name: "名称", Type: 1, UiType: "Text", IsPrimary: true})
+	srv.AddField("app", "tbl", Field{Name: "序号", Type: 2, UiType: "Number"})
+	for body, code := range map[string]int{
+		`{"field_names":["负责人"]}`:         CodeFieldNameNotFound,
+		`{"sort":[{"field_name":"负责人"}]}`: CodeFieldNameNotFound,
+		`{"filter":{"conjunction":"and","children":[{"conjunction":"and","conditions":[
+			{"field_name":"负责人","operator":"isEmpty","value":[]}]}]}}`: CodeInvalidFilter,
+	} {
+		if raw, _ := search(body, ""); raw["code"].(float64) != float64(code) {
+			t.Errorf("search(%s) response = %v, want code %d", body, raw, code)
+		}
+	}
+	_, names = search(`{"sort":[{"field_name":"序号"}],"field_names":["名称"]}`, "")
+	assertNames(names, "写文档", "评审文档", "发布", "复盘")
+}
+
+func TestSchema(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.AddApp("app", "项目管理")
+	cli := srv.Client()
+	ctx := context.Background()
+
+	created, err := cli.Bitable.V1.AppTable.Create(ctx, larkbitable.NewCreateAppTableReqBuilder().AppToken("app").
+		Body(larkbitable.NewCreateAppTableReqBodyBuilder().Table(larkbitable.NewReqTableBuilder().Name("任务").Build()).Build()).
+		Build())
+	if err != nil || !created.Success() || len(*created.Data.TableId) != 16 {
+		t.Fatalf("AppTable.Create() = %+v, %v", created, err)
+	}
+	tableId := *created.Data.TableId
+	if again, _ := cli.Bitable.V1.AppTable.Create(ctx, larkbitable.NewCreateAppTableReqBuilder().AppToken("app").
+		Body(larkbitable.NewCreateAppTableReqBodyBuilder().Table(larkbitable.NewReqTableBuilder().Name("任务").Build()).Build()).
+		Build()); again.Code != CodeTableNameDuplicated {
+		t.Errorf("duplicated table code = %d", again.Code)
+	}
+	if fields := srv.Fields("app", tableId); len(fields) != 1 || !fields[0].IsPrimary || fields[0].Name != "多行文本" {
+		t.Errorf("default fields = %+v", fields)
+	}
+	if views := srv.Views("app", tableId); len(views) != 1 || views[0].Type != "grid" {
+		t.Errorf("default views = %+v", views)
+	}
+
+	fieldsApi := cli.Bitable.V1.AppTableField
+	field, err := fieldsApi.Create(ctx, larkbitable.NewCreateAppTableFieldReqBuilder().AppToken("app").TableId(tableId).
+		AppTableField(larkbitable.NewAppTableFieldBuilder().FieldName("状态").Type(3).
+			Property(larkbitable.NewAppTableFieldPropertyBuilder().Options([]*larkbitable.AppTableFieldPropertyOption{
+				larkbitable.NewAppTableFieldPropertyOptionBuilder().Name("进行中").Build(),
+			}).Build()).
+			Build()).
+		Build())
+	if err != nil || !field.Success() || *field.Data.Field.Property.Options[0].Id == "" {
+		t.Fatalf("AppTableField.Create() = %+v, %v", field, err)
+	}
+	fieldId := *field.Data.Field.FieldId
+
+	// 记录中的字段必须存在
+	recordsApi := cli.Bitable.V1.AppTableRecord
+	rec, _ := recordsApi.Create(ctx, larkbitable.NewCreateAppTableRecordReqBuilder().AppToken("app").TableId(tableId).
+		AppTableRecord(larkbitable.NewAppTableRecordBuilder().Fields(map[string]interface{}{"负责人": "张三"}).Build()).Build())
+	if rec.Code != CodeFieldNameNotFound {
+		t.Errorf("unknown field code = %d", rec.Code)
+	}
+	srv.Insert("app", tableId, map[string]interface{}{"状态": "进行中"})
+
+	updated, err := fieldsApi.Update(ctx, larkbitable.NewUpdateAppTableFieldReqBuilder().AppToken("app").TableId(tableId).FieldId(fieldId).
+		AppTableField(larkbitable.NewAppTableFieldBuilder().FieldName("阶段").Type(3).Build()).
+		Build())
+	if err != nil || !updated.Success() || *updated.Data.Field.FieldName != "阶段" {
+		t.Fatalf("AppTableField.Update() = %+v, %v", updated, err)
+	}
+	if records := srv.Records("app", tableId); records[0].Fields["阶段"] != "进行中" {
+		t.Errorf("records after rename = %+v", records)
+	}
+	if deleted, _ := fieldsApi.Delete(ctx, larkbitable.NewDeleteAppTableFieldReqBuilder().AppToken("app").TableId(tableId).FieldId(fieldId).Build()); !deleted.Success() {
+		t.Errorf("AppTableField.Delete() = %+v", deleted)
+	}
+	list, _ := fieldsApi.List(ctx, larkbitable.NewListAppTableFieldReqBuilder().AppToken("app").TableId(tableId).Build())
+	if len(list.Data.Items) != 1 || *list.Data.Total != 1 {
+		t.Errorf("fields after delete = %+v", list.Data.Items)
+	}
+
+	viewsApi := cli.Bitable.V1.AppTableView
+	view, err := viewsApi.Create(ctx, larkbitable.NewCreateAppTableViewReqBuilder().AppToken("app").TableId(tableId).
+		ReqView(larkbitable.NewReqViewBuilder().ViewName("看板").ViewType("kanban").Build()).Build())
+	if err != nil || !view.Success() || *view.Data.View.ViewType != "kanban" {
+		t.Fatalf("AppTableView.Create() = %+v, %v", view, err)
+	}
+	if got, _ := viewsApi.Get(ctx, larkbitable.NewGetAppTableViewReqBuilder().AppToken("app").TableId(tableId).ViewId("vewMissing").Build()); got.Code != CodeViewNotFound {
+		t.Errorf("missing view code = %d", got.Code)
+	}
+
+	renamed, _ := cli.Bitable.V1.AppTable.Patch(ctx, larkbitable.NewPatchAppTableReqBuilder().AppToken("app").TableId(tableId).
+		Body(larkbitable.NewPatchAppTableReqBodyBuilder().Name("任务（归档）").Build()).Build())
+	if !renamed.Success() || srv.Tables("app")[0].Name != "任务（归档）" {
+		t.Errorf("AppTable.Patch() = %+v, tables %v", renamed, srv.Tables("app"))
+	}
+	if deleted, _ := cli.Bitable.V1.AppTable.BatchDelete(ctx, larkbitable.NewBatchDeleteAppTableReqBuilder().AppToken("app").
+		Body(larkbitable.NewBatchDeleteAppTableReqBodyBuilder().TableIds([]string{}).Build()).Build()); deleted.Code != CodeInvalidParam {
+		t.Errorf("empty batch delete code = %d", deleted.Code)
+	}
+	if deleted, _ := cli.Bitable.V1.AppTable.Delete(ctx, larkbitable.NewDeleteAppTableReqBuilder().AppToken("app").TableId(tableId).Build()); !deleted.Success() || len(srv.Tables("app")) != 0 {
+		t.Errorf("AppTable.Delete() = %+v", deleted)
+	}
+}
@@ -2,16 +2,18 @@ package biorm
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"net/http"
 	"strings"
 
+	"github.com/2015WUJI01/biorm/logger"
 	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
 	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
 )
 
 // Base 选择飞书表格
 func (db *DB) Base(appToken string, args ...interface{}) (tx *DB) {
-	log.Printf("[Base调试] 设置appToken=%s", appToken)
+	db.debugf("[Base] 设置appToken=%s", appToken)
 	tx = db.getInstance()
 	tx.AppToken = appToken
 	return tx
@@ -29,7 +31,7 @@ func (db *DB) BaseTable(combinedId string, args ...interface{}) (tx *DB) {
 
 // Wiki 选择飞书表格
 func (db *DB) Wiki(appToken string, args ...interface{}) (tx *DB) {
-	log.Printf("[Wiki调试] 开始处理appToken=%s", appToken)
+	db.debugf("[Wiki] 开始处理appToken=%s", appToken)
 	tx = db.getInstance()
 	if tx.hasError() {
 		return
@@ -43,18 +45,23 @@ func (db *DB) Wiki(appToken string, args ...interface{}) (tx *DB) {
 	req := larkwiki.NewGetNodeSpaceReqBuilder().Token(appToken).ObjType(`wiki`).Build()
 
 	// 发起请求
-	resp, err := tx.cli.Wiki.V2.Space.GetNode(context.Background(), req)
+	var resp *larkwiki.GetNodeSpaceResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodGet,
+		Path:       "/open-apis/wiki/v2/spaces/get_node",
+		Body:       nil,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Wiki.V2.Space.GetNode(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
-		}
+	if !tx.checkResponse(resp, err) {
 		return
 	}
-	if resp == nil {
+	if resp.Data == nil || resp.Data.Node == nil || resp.Data.Node.ObjType == nil || resp.Data.Node.ObjToken == nil {
 		tx.Error = ErrResponseIsNil
 		return
 	}
@@ -66,13 +73,13 @@ func (db *DB) Wiki(appToken string, args ...interface{}) (tx *DB) {
 
 	// 处理业务
 	tx.AppToken = *resp.Data.Node.ObjToken
-	log.Printf("[Wiki调试] 设置appToken=%s", tx.AppToken)
+	tx.debugf("[Wiki] 设置appToken=%s", tx.AppToken)
 	return tx
 }
 
 // WikiTable 使用拼接后的 ID 值作为表格标识符
 func (db *DB) WikiTable(combinedId string, args ...interface{}) (tx *DB) {
-	log.Printf("[WikiTable调试] 接收combinedId=%s", combinedId)
+	db.debugf("[WikiTable] 接收combinedId=%s", combinedId)
 
 	parts := strings.Split(combinedId, ".")
 	if len(parts) != 2 {
@@ -80,28 +87,80 @@ func (db *DB) WikiTable(combinedId string, args ...interface{}) (tx *DB) {
 		return db
 	}
 
-	log.Printf("[WikiTable调试] 解析成功: appToken=%s, tableId=%s", parts[0], parts[1])
+	db.debugf("[WikiTable] 解析成功: appToken=%s, tableId=%s", parts[0], parts[1])
 	tx = db.Wiki(parts[0]).Table(parts[1])
 
 	// 检查实例状态
 	if tx != nil && tx.Statement.Filter.Conjunction != nil {
-		log.Printf("[WikiTable调试] 返回的实例: appToken=%s, tableId=%s, filter.conjunction=%s, filter.conditions长度=%d",
+		tx.debugf("[WikiTable] 返回的实例: appToken=%s, tableId=%s, filter.conjunction=%s, filter.conditions长度=%d",
 			tx.AppToken, tx.TableId, *tx.Statement.Filter.Conjunction, len(tx.Statement.Filter.Conditions))
 	} else if tx != nil {
-		log.Printf("[WikiTable调试] 返回的实例: appToken=%s, tableId=%s, filter.conjunction=nil",
+		tx.debugf("[WikiTable] 返回的实例: appToken=%s, tableId=%s, filter.conjunction=nil",
 			tx.AppToken, tx.TableId)
 	}
 
 	return tx
 }
 
+// Table 选择数据表，tableId 不是数据表 ID（tbl 加 13 到 16 位字母、数字）时按数据表名称查找对应的 ID
+// Usage:
+//
+//	db.Base(appToken).Table("tbl0xe5g8PP3U3cS")
+//	db.Base(appToken).Table("任务")
 func (db *DB) Table(tableId string, args ...interface{}) (tx *DB) {
-	log.Printf("[Table调试] 设置tableId=%s", tableId)
+	db.debugf("[Table] 设置tableId=%s", tableId)
 	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	if tableId != "" && !isTableId(tableId) {
+		if tx.AppToken == "" {
+			tx.Error = ErrAppTokenRequired
+			return
+		}
+		resolved, err := tx.resolveTableId(tableId)
+		if err != nil {
+			tx.Error = fmt.Errorf("%w: %s", err, tableId)
+			return
+		}
+		tx.debugf("[Table] 数据表 %s 的 ID 为 %s", tableId, resolved)
+		tableId = resolved
+	}
 	tx.TableId = tableId
 	return tx
 }
 
+// Debug 以调试模式执行，输出条件构建过程以及每一次请求的日志
+func (db *DB) Debug() (tx *DB) {
+	tx = db.getInstance()
+	config := *tx.Config
+	config.Logger = tx.getLogger().LogMode(logger.Debug)
+	tx.Config = &config
+	return tx
+}
+
+// WithContext 设置请求使用的 context，用于控制超时与取消
+// Usage:
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+//	defer cancel()
+//	records, tx := db.WithContext(ctx).Base(appToken).Table(tableId).Records()
+func (db *DB) WithContext(ctx context.Context) (tx *DB) {
+	tx = db.getInstance()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	tx.Statement.Context = ctx
+	return tx
+}
+
+// AllowGlobalUpdate 允许在没有任何条件时执行 Updates、Delete，作用于整张数据表
+func (db *DB) AllowGlobalUpdate() (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.AllowGlobalUpdate = true
+	return tx
+}
+
 // Idempotent 描述：格式为标准的 uuid，操作的唯一标识，用于幂等的进行更新操作。此值为空表示将发起一次新的请求，此值非空表示幂等的进行更新操作。
 // 示例值：fe599b60-450f-46ff-b2ef-9f6675625b97
 func (db *DB) Idempotent(clientToken string) (tx *DB) {
@@ -133,6 +192,35 @@ func (db *DB) Order(fieldName string, desc ...bool) (tx *DB) {
 	return
 }
 
+// Limit 描述：最多返回的记录数，n 小于等于 0 时不限制
+// Usage:
+//
+//	// 查询创建时间最新的 10 条记录
+//	db.Order("创建时间", true).Limit(10).Records()
+func (db *DB) Limit(n int) (tx *DB) {
+	tx = db.getInstance()
+	if n < 0 {
+		n = 0
+	}
+	tx.Statement.Limit = n
+	return
+}
+
+// Offset 描述：跳过前 n 条记录，n 小于等于 0 时不跳过
+// 查询接口不支持偏移，跳过的记录仍需按页请求，每次请求最多跳过 MaxPageSize 条
+// Usage:
+//
+//	// 查询第 21 ~ 30 条记录
+//	db.Order("创建时间").Offset(20).Limit(10).Records()
+func (db *DB) Offset(n int) (tx *DB) {
+	tx = db.getInstance()
+	if n < 0 {
+		n = 0
+	}
+	tx.Statement.Offset = n
+	return
+}
+
 //func (db *DB) Where(fieldName string, operator string, value *[]string) (tx *DB) {
 //	tx = db.getInstance()
 //	if conds := tx.Statement.BuildCondition(query, args...); len(conds) > 0 {
@@ -141,83 +229,100 @@ func (db *DB) Order(fieldName string, desc ...bool) (tx *DB) {
 //	return
 //}
 
-// Where 描述：查询条件，支持使用 SQL 语法。
+// Where 描述：查询条件，支持使用 SQL 语法，多个 Where 之间使用 AND 连接。
+// 传入 func(*DB) *DB 时，函数内构建的条件会作为一个条件组。
 // Usage:
 //
 //	// 查询 职位 为 "初级销售员" 的记录
 //	db.Where("职位 = ?", "初级销售员")
 //	// 查询 name 为 "jinzhu" 且 age 不为 20 的记录
 //	db.Where("name = ?", "jinzhu").Where("age <> ?", "20")
-func (db *DB) Where(query string, args ...interface{}) (tx *DB) {
-	log.Printf("[Where调试] 原始db.Filter条件 Conjunction=%v, Conditions长度=%d",
-		db.Statement.Filter.Conjunction, len(db.Statement.Filter.Conditions))
-
-	tx = db.getInstance()
-	if tx.hasError() {
-		return tx
-	}
-
-	// 确保默认是 AND 连接条件
-	and := "and"
-	tx.Statement.Filter.Conjunction = &and
-
-	log.Printf("[Where调试] 设置db.Filter.Conjunction=%s", *tx.Statement.Filter.Conjunction)
-	log.Printf("[Where调试] Query=%s, Args=%v", query, args)
-
-	tx.Statement.BuildCondition(query, args...)
-
-	log.Printf("[Where调试] 构建完条件后 tx.Statement.Filter.Conditions长度=%d",
-		len(tx.Statement.Filter.Conditions))
-	for i, cond := range tx.Statement.Filter.Conditions {
-		if cond != nil && cond.FieldName != nil && cond.Operator != nil {
-			log.Printf("[Where调试] 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
-				i, *cond.FieldName, *cond.Operator, cond.Value)
-		}
-	}
-
-	return tx
+//	// 查询 状态 为 "进行中" 且 (优先级 为 "P0" 或 负责人 为空) 的记录
+//	db.Where("状态 = ?", "进行中").Where(func(g *biorm.DB) *biorm.DB {
+//		return g.Where("优先级 = ?", "P0").Or("负责人 isEmpty")
+//	})
+func (db *DB) Where(query interface{}, args ...interface{}) (tx *DB) {
+	return db.addFilter("Where", conjunctionAnd, false, query, args...)
 }
 
-// Or 描述：查询条件，支持使用 SQL 语法。
+// Or 描述：查询条件，支持使用 SQL 语法，与之前的全部条件使用 OR 连接。
 // Usage:
 //
 //	// 查询 职位 为 "初级销售员" 的记录
 //	db.Or("职位 = ?", "初级销售员")
-//	// 查询 name 为 "jinzhu" 且 age 不为 20 的记录
-//	db.Or("name = ?", "jinzhu").Or("age <> ?", "20").First(&user)
-func (db *DB) Or(query string, args ...interface{}) (tx *DB) {
-	log.Printf("[Or调试] 原始db.Filter条件 Conjunction=%v, Conditions长度=%d",
-		db.Statement.Filter.Conjunction, len(db.Statement.Filter.Conditions))
+//	// 查询 name 为 "jinzhu" 或 age 不为 20 的记录
+//	db.Where("name = ?", "jinzhu").Or("age <> ?", "20")
+//	// (a OR b) AND c
+//	db.Where("a = ?", 1).Or("b = ?", 2).Where("c = ?", 3)
+func (db *DB) Or(query interface{}, args ...interface{}) (tx *DB) {
+	return db.addFilter("Or", conjunctionOr, false, query, args...)
+}
+
+// Not 描述：取反的查询条件，与之前的全部条件使用 AND 连接。
+// Usage:
+//
+//	// 查询 状态 不为 "已完成" 的记录
+//	db.Not("状态 = ?", "已完成")
+//	// 查询 既不是 P0 也不是 P1 的记录
+//	db.Not(func(g *biorm.DB) *biorm.DB {
+//		return g.Where("优先级 = ?", "P0").Or("优先级 = ?", "P1")
+//	})
+func (db *DB) Not(query interface{}, args ...interface{}) (tx *DB) {
+	return db.addFilter("Not", conjunctionAnd, true, query, args...)
+}
 
+// addFilter 构建条件并使用 conjunction 连接到已有条件上
+func (db *DB) addFilter(method, conjunction string, negate bool, query interface{}, args ...interface{}) (tx *DB) {
 	tx = db.getInstance()
 	if tx.hasError() {
 		return tx
 	}
 
-	// 设置为 OR 连接条件
-	or := "or"
-	tx.Statement.Filter.Conjunction = &or
-
-	log.Printf("[Or调试] 设置db.Filter.Conjunction=%s", *tx.Statement.Filter.Conjunction)
-	log.Printf("[Or调试] Query=%s, Args=%v", query, args)
-
-	tx.Statement.BuildCondition(query, args...)
+	tx.debugf("[%s] Query=%v, Args=%v", method, query, args)
 
-	log.Printf("[Or调试] 构建完条件后 tx.Statement.Filter.Conditions长度=%d",
-		len(tx.Statement.Filter.Conditions))
-	for i, cond := range tx.Statement.Filter.Conditions {
-		if cond != nil && cond.FieldName != nil && cond.Operator != nil {
-			log.Printf("[Or调试] 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
-				i, *cond.FieldName, *cond.Operator, cond.Value)
+	filter := tx.Statement.BuildCondition(query, args...)
+	if tx.hasError() {
+		return tx
+	}
+	if negate {
+		negated, err := negateFilter(filter)
+		if err != nil {
+			tx.Error = err
+			return tx
 		}
+		filter = negated
 	}
+	tx.Statement.AddFilter(conjunction, filter)
+
+	tx.debugf("[%s] 构建完条件后 conjunction=%s, conditions长度=%d, children长度=%d",
+		method, filterConjunction(tx.Statement.Filter), len(tx.Statement.Filter.Conditions), len(tx.Statement.Filter.Children))
 
 	return tx
 }
 
-// View 指定视图ID
-func (db *DB) View(viewId string) *DB {
-	tx := db.getInstance()
+// View 指定视图，可以传入视图 ID 或视图名称，传入名称时需要先指定数据表
+func (db *DB) View(viewId string) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	if viewId != "" && !isViewId(viewId) {
+		if tx.AppToken == "" {
+			tx.Error = ErrAppTokenRequired
+			return
+		}
+		if tx.TableId == "" {
+			tx.Error = ErrTableIdRequired
+			return
+		}
+		resolved, err := tx.resolveViewId(viewId)
+		if err != nil {
+			tx.Error = fmt.Errorf("%w: %s", err, viewId)
+			return
+		}
+		tx.debugf("[View] 视图 %s 的 ID 为 %s", viewId, resolved)
+		viewId = resolved
+	}
 	tx.Statement.ViewId = viewId
 	return tx
 }
@@ -229,8 +334,7 @@ func (db *DB) AutomaticFields(automaticFields bool) *DB {
 	return tx
 }
 
-// BatchGet 通过记录ID批量查询记录
-// recordIds 是记录ID的字符串数组。一次最多传入1000个recordId，超出部分将会被忽略
+// BatchGetRecords 通过记录ID批量查询记录，等同于 BatchGet
 func (db *DB) BatchGetRecords(recordIds []string) ([]*larkbitable.AppTableRecord, *DB) {
 	return db.BatchGet(recordIds)
 }
@@ -0,0 +1,90 @@
+package biorm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"unsafe"
+
+	lark "github.com/larksuite/oapi-sdk-go/v3"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	"github.com/larksuite/oapi-sdk-go/v3/service/bitable"
+	"github.com/larksuite/oapi-sdk-go/v3/service/wiki"
+)
+
+// apiClient 发起请求使用的 SDK 服务
+type apiClient struct {
+	Bitable *bitable.Service
+	Wiki    *wiki.Service
+
+	cli    *lark.Client
+	config *larkcore.Config // 替换域名后的配置，未设置 Config.BaseUrl 时为 nil
+}
+
+// Do 发起 biorm 自行构造的请求，例如查询记录
+func (c *apiClient) Do(ctx context.Context, req *larkcore.ApiReq) (*larkcore.ApiResp, error) {
+	if c.config == nil {
+		return c.cli.Do(ctx, req)
+	}
+	return larkcore.Request(ctx, req, c.config)
+}
+
+// apiClients 按域名缓存 apiClient，同一个 NewDB 创建的实例共享
+type apiClients struct {
+	mu      sync.Mutex
+	clients map[string]*apiClient
+}
+
+// api 返回发起请求使用的 SDK 服务，设置了 Config.BaseUrl 时全部请求（包括获取 access token）都发往该域名
+func (db *DB) api() *apiClient {
+	c, _ := db.resolveApi()
+	return c
+}
+
+// resolveApi 返回 Config.BaseUrl 对应的 SDK 服务，无法读取 lark.Client 的配置时返回 ErrBaseUrlUnsupported
+func (db *DB) resolveApi() (*apiClient, error) {
+	direct := &apiClient{cli: db.cli}
+	if db.cli != nil {
+		direct.Bitable, direct.Wiki = db.cli.Bitable, db.cli.Wiki
+	}
+	baseUrl := ""
+	if db.Config != nil {
+		baseUrl = strings.TrimSuffix(db.Config.BaseUrl, "/")
+	}
+	if baseUrl == "" || db.cli == nil || db.apis == nil {
+		return direct, nil
+	}
+
+	db.apis.mu.Lock()
+	defer db.apis.mu.Unlock()
+	if c, ok := db.apis.clients[baseUrl]; ok {
+		return c, nil
+	}
+	origin := clientConfig(db.cli)
+	if origin == nil {
+		return direct, ErrBaseUrlUnsupported
+	}
+	config := *origin
+	config.BaseUrl = baseUrl
+	c := &apiClient{
+		Bitable: bitable.NewService(&config),
+		Wiki:    wiki.NewService(&config),
+		cli:     db.cli,
+		config:  &config,
+	}
+	if db.apis.clients == nil {
+		db.apis.clients = make(map[string]*apiClient)
+	}
+	db.apis.clients[baseUrl] = c
+	return c, nil
+}
+
+// clientConfig 读取 lark.Client 未导出的配置，SDK 的结构发生变化时返回 nil
+func clientConfig(cli *lark.Client) *larkcore.Config {
+	v := reflect.ValueOf(cli).Elem().FieldByName("config")
+	if !v.IsValid() || v.Type() != reflect.TypeOf((*larkcore.Config)(nil)) || v.IsNil() {
+		return nil
+	}
+	return (*larkcore.Config)(unsafe.Pointer(v.Pointer()))
+}
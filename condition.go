@@ -0,0 +1,151 @@
+package biorm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// buildMapCondition 将 map 转换为过滤条件，多个字段之间使用 AND 连接
+// 值为切片时展开为 in 条件，值为 nil 时生成 isEmpty 条件
+func buildMapCondition(m map[string]interface{}) (larkbitable.FilterInfo, error) {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var filter larkbitable.FilterInfo
+	for _, name := range names {
+		cond, err := buildFieldCondition(name, m[name], true)
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = combineFilter(conjunctionAnd, filter, cond); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return filter, nil
+}
+
+// buildStructCondition 将带 biorm tag 的结构体转换为过滤条件，只包含非零值字段
+// fields 可以指定需要包含的字段（多维表格字段名或结构体字段名），此时即使是零值也会作为条件
+func buildStructCondition(v reflect.Value, fields ...interface{}) (larkbitable.FilterInfo, error) {
+	s, err := parseSchema(v.Type())
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+
+	selected := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		name, ok := field.(string)
+		if !ok {
+			return larkbitable.FilterInfo{}, fmt.Errorf("%w: 结构体条件的字段名必须是 string，实际为 %T", ErrUnsupportedModel, field)
+		}
+		selected[name] = true
+	}
+
+	var filter larkbitable.FilterInfo
+	for _, f := range s.Fields {
+		if f.IsRecordId {
+			continue
+		}
+		fv, ok := readField(v, f)
+		if len(selected) > 0 {
+			if !selected[f.Name] && !selected[f.GoName] {
+				continue
+			}
+		} else if !ok || fv.IsZero() {
+			continue
+		}
+
+		var value interface{}
+		if ok {
+			value = conditionValueOf(fv)
+		}
+		cond, err := buildFieldCondition(f.Name, value, false)
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = combineFilter(conjunctionAnd, filter, cond); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return filter, nil
+}
+
+// buildFieldCondition 生成单个字段的条件
+// expandSlice 为 true 时切片值展开为 in 条件，否则作为 is 条件的多个值
+func buildFieldCondition(name string, value interface{}, expandSlice bool) (larkbitable.FilterInfo, error) {
+	if value == nil {
+		op := "isEmpty"
+		return newConditionFilter(&larkbitable.Condition{FieldName: &name, Operator: &op, Value: []string{}}), nil
+	}
+
+	if expandSlice {
+		if _, isBytes := value.([]byte); !isBytes {
+			if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+				values := make([]interface{}, 0, rv.Len())
+				for i := 0; i < rv.Len(); i++ {
+					values = append(values, rv.Index(i).Interface())
+				}
+				return inFilter(name, values)
+			}
+		}
+	}
+
+	return buildCondition(name, "is", value)
+}
+
+// inFilter 生成字段等于任意一个值的条件，多个值时展开为 OR 条件组
+func inFilter(name string, values []interface{}) (larkbitable.FilterInfo, error) {
+	if len(values) == 0 {
+		return larkbitable.FilterInfo{}, fmt.Errorf("%w: %s", ErrEmptyInValues, name)
+	}
+
+	or := conjunctionOr
+	filter := larkbitable.FilterInfo{Conjunction: &or}
+	for _, value := range values {
+		cond, err := buildCondition(name, "is", value)
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = combineFilter(conjunctionOr, filter, cond); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return filter, nil
+}
+
+// conditionValueOf 将模型字段值转换为筛选条件使用的值
+// 人员字段使用人员 ID，关联字段使用关联记录 ID
+func conditionValueOf(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return conditionValueOf(v.Elem())
+	}
+
+	switch v.Type() {
+	case personType:
+		return v.Interface().(Person).Id
+	case linkType:
+		return v.Interface().(Link).RecordIds
+	case urlType:
+		return v.Interface().(Url).Link
+	}
+
+	if v.Kind() == reflect.Slice && v.Type().Elem() != reflect.TypeOf(byte(0)) {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			encoded, _ := encodeConditionValue("is", conditionValueOf(v.Index(i)))
+			values = append(values, encoded...)
+		}
+		return values
+	}
+	return v.Interface()
+}
@@ -0,0 +1,67 @@
+package biorm
+
+import (
+	"errors"
+	"testing"
+)
+
+type conditionTask struct {
+	RecordId string   `biorm:"record_id"`
+	Status   string   `biorm:"状态"`
+	Priority int      `biorm:"优先级"`
+	Owner    Person   `biorm:"负责人"`
+	Tags     []string `biorm:"标签"`
+}
+
+func TestWhereMapAndStruct(t *testing.T) {
+	db := NewDB(nil)
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "map",
+			tx: db.Where(map[string]interface{}{
+				"状态":  "进行中",
+				"优先级": []string{"P0", "P1"},
+				"备注":  nil,
+			}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"备注","operator":"isEmpty","value":[]},
+				{"field_name":"状态","operator":"is","value":["进行中"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"优先级","operator":"is","value":["P0"]},
+					{"field_name":"优先级","operator":"is","value":["P1"]}]}]}`,
+		},
+		{
+			name: "struct non-zero fields",
+			tx:   db.Where(&conditionTask{RecordId: "rec1", Status: "进行中", Owner: Person{Id: "ou_1"}, Tags: []string{"A", "B"}}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]},
+				{"field_name":"负责人","operator":"is","value":["ou_1"]},
+				{"field_name":"标签","operator":"is","value":["A","B"]}]}`,
+		},
+		{
+			name: "struct selected zero field",
+			tx:   db.Where(conditionTask{Status: "进行中"}, "状态", "Priority"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]},
+				{"field_name":"优先级","operator":"is","value":["0"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+}
+
+func TestWhereMapEmptySlice(t *testing.T) {
+	tx := NewDB(nil).Where(map[string]interface{}{"优先级": []string{}})
+	if !errors.Is(tx.Error, ErrEmptyInValues) {
+		t.Errorf("expected ErrEmptyInValues, got %v", tx.Error)
+	}
+}
@@ -0,0 +1,322 @@
+package biorm
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// 日期筛选条件的取值
+const (
+	dateExact        = "ExactDate"    // 具体日期
+	dateToday        = "Today"        // 今天
+	dateTomorrow     = "Tomorrow"     // 明天
+	dateYesterday    = "Yesterday"    // 昨天
+	dateCurrentWeek  = "CurrentWeek"  // 本周
+	dateLastWeek     = "LastWeek"     // 上周
+	dateCurrentMonth = "CurrentMonth" // 本月
+	dateLastMonth    = "LastMonth"    // 上个月
+	dateTheLastWeek  = "TheLastWeek"  // 过去 7 天
+	dateTheNextWeek  = "TheNextWeek"  // 未来 7 天
+	dateTheLastMonth = "TheLastMonth" // 过去 30 天
+	dateTheNextMonth = "TheNextMonth" // 未来 30 天
+
+	formulaDateLayout = "2006/01/02"
+)
+
+// timeNow 返回当前时间，测试时可以替换
+var timeNow = time.Now
+
+// DefaultLocation 计算日期使用的默认时区，默认为 UTC+8，应与多维表格的文档时区一致；
+// 用于计算相对今天的日期，以及开启 ValidateFields 时解析 "2006-01-02" 等日期文本，
+// 单个日期值可以通过 DateValue.In 指定时区
+var DefaultLocation = time.FixedZone("UTC+8", 8*60*60)
+
+// DateValue 日期字段的筛选值，可以是具体日期、相对今天的日期、本周等时间段或日期范围
+//
+// 多维表格的日期条件只支持 is、isGreater、isLess、isEmpty、isNotEmpty，
+// 其它比较运算符以及日期范围会被转换为等价的条件组合：
+//
+//	>= d  ->  isGreater 前一天
+//	<= d  ->  isLess 后一天
+//	!= d  ->  isLess d OR isGreater d
+//	= [from, to]  ->  isGreater from 前一天 AND isLess to 后一天
+//
+// 使用 Not 取反日期条件时同样按上面的规则转换，时间段取反会返回 ErrUnsupportedDateOperator
+//
+// Usage:
+//
+//	db.Where("截止日期 = ?", biorm.Today())
+//	db.Where("创建日期 >= ?", biorm.ExactDate(t))
+//	db.Where(biorm.F("创建日期").Eq(biorm.LastNDays(30)))
+type DateValue struct {
+	period   string         // CurrentWeek 等只能用于 is 的时间段
+	relative bool           // 是否为相对今天的日期
+	offset   int            // relative 为 true 时相对今天的天数
+	date     time.Time      // 具体日期
+	end      *DateValue     // 不为空时表示从当前日期到 end 的日期范围（包含两端）
+	loc      *time.Location // 计算日期使用的时区
+	formula  bool           // 是否为公式字段
+}
+
+// ExactDate 具体日期，只使用 t 在其时区中的年月日
+func ExactDate(t time.Time) DateValue {
+	return DateValue{date: t, loc: t.Location()}
+}
+
+// Today 今天
+func Today() DateValue { return DateValue{relative: true} }
+
+// Tomorrow 明天
+func Tomorrow() DateValue { return DateValue{relative: true, offset: 1} }
+
+// Yesterday 昨天
+func Yesterday() DateValue { return DateValue{relative: true, offset: -1} }
+
+// CurrentWeek 本周，只能用于 = 条件
+func CurrentWeek() DateValue { return DateValue{period: dateCurrentWeek} }
+
+// LastWeek 上周，只能用于 = 条件
+func LastWeek() DateValue { return DateValue{period: dateLastWeek} }
+
+// CurrentMonth 本月，只能用于 = 条件
+func CurrentMonth() DateValue { return DateValue{period: dateCurrentMonth} }
+
+// LastMonth 上个月，只能用于 = 条件
+func LastMonth() DateValue { return DateValue{period: dateLastMonth} }
+
+// TheLastWeek 过去 7 天，只能用于 = 条件
+func TheLastWeek() DateValue { return DateValue{period: dateTheLastWeek} }
+
+// TheNextWeek 未来 7 天，只能用于 = 条件
+func TheNextWeek() DateValue { return DateValue{period: dateTheNextWeek} }
+
+// TheLastMonth 过去 30 天，只能用于 = 条件
+func TheLastMonth() DateValue { return DateValue{period: dateTheLastMonth} }
+
+// TheNextMonth 未来 30 天，只能用于 = 条件
+func TheNextMonth() DateValue { return DateValue{period: dateTheNextMonth} }
+
+// LastNDays 包含今天在内的最近 n 天
+func LastNDays(n int) DateValue {
+	if n < 1 {
+		n = 1
+	}
+	return DateValue{relative: true, offset: 1 - n, end: &DateValue{relative: true}}
+}
+
+// NextNDays 从今天开始的 n 天
+func NextNDays(n int) DateValue {
+	if n < 1 {
+		n = 1
+	}
+	return DateValue{relative: true, end: &DateValue{relative: true, offset: n - 1}}
+}
+
+// DateBetween 从 from 到 to 的日期范围，包含两端
+func DateBetween(from, to time.Time) DateValue {
+	end := ExactDate(to)
+	d := ExactDate(from)
+	d.end = &end
+	return d
+}
+
+// In 使用 loc 计算今天以及具体日期的年月日，默认为 DefaultLocation；通常应设置为多维表格的文档时区
+func (d DateValue) In(loc *time.Location) DateValue {
+	d.loc = loc
+	if d.end != nil {
+		end := d.end.In(loc)
+		d.end = &end
+	}
+	return d
+}
+
+// Formula 用于公式字段，具体日期会被编码为 yyyy/MM/dd 格式的日期文本
+func (d DateValue) Formula() DateValue {
+	d.formula = true
+	if d.end != nil {
+		end := d.end.Formula()
+		d.end = &end
+	}
+	return d
+}
+
+// String 返回日期值的描述，用于日志与错误信息
+func (d DateValue) String() string {
+	if d.end != nil {
+		return d.start().String() + " ~ " + d.end.String()
+	}
+	if d.period != "" {
+		return d.period
+	}
+	return d.day().Format("2006-01-02")
+}
+
+// start 返回日期范围的开始日期
+func (d DateValue) start() DateValue {
+	d.end = nil
+	return d
+}
+
+// location 返回计算日期使用的时区
+func (d DateValue) location() *time.Location {
+	if d.loc == nil {
+		return DefaultLocation
+	}
+	return d.loc
+}
+
+// day 返回单个日期在时区中的零点
+func (d DateValue) day() time.Time {
+	t := d.date
+	if d.relative {
+		t = timeNow().AddDate(0, 0, d.offset)
+	}
+	t = t.In(d.location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, d.location())
+}
+
+// shift 返回前后 days 天的日期
+func (d DateValue) shift(days int) DateValue {
+	if d.relative {
+		d.offset += days
+		return d
+	}
+	d.date = d.day().AddDate(0, 0, days)
+	return d
+}
+
+// values 编码单个日期为筛选条件的 value
+func (d DateValue) values() []string {
+	if d.period != "" {
+		return []string{d.period}
+	}
+	if d.relative && !d.formula {
+		switch d.offset {
+		case 0:
+			return []string{dateToday}
+		case 1:
+			return []string{dateTomorrow}
+		case -1:
+			return []string{dateYesterday}
+		}
+	}
+
+	day := d.day()
+	if d.formula {
+		return []string{dateExact, day.Format(formulaDateLayout)}
+	}
+	// 接口会将时间戳转换为文档时区当天的零点，使用 UTC 正午避免时区差异导致日期偏移一天
+	noon := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, time.UTC)
+	return []string{dateExact, fmt.Sprintf("%d", noon.UnixMilli())}
+}
+
+// dateValueOf 将 time.Time 与 DateValue 转换为 DateValue
+func dateValueOf(value interface{}) (DateValue, bool) {
+	switch v := value.(type) {
+	case DateValue:
+		return v, true
+	case *DateValue:
+		if v != nil {
+			return *v, true
+		}
+	case time.Time:
+		return ExactDate(v), true
+	case *time.Time:
+		if v != nil {
+			return ExactDate(*v), true
+		}
+	}
+	return DateValue{}, false
+}
+
+// conditionDateValue 从日期条件编码后的 value 还原日期，用于对日期条件取反；
+// 具体日期以 UTC 还原，与编码时使用的 UTC 正午为同一天
+func conditionDateValue(cond *larkbitable.Condition) (DateValue, bool) {
+	if cond.Operator == nil || *cond.Operator != "is" && *cond.Operator != "isGreater" && *cond.Operator != "isLess" {
+		return DateValue{}, false
+	}
+	switch v := cond.Value; {
+	case len(v) == 1:
+		switch v[0] {
+		case dateToday:
+			return Today(), true
+		case dateTomorrow:
+			return Tomorrow(), true
+		case dateYesterday:
+			return Yesterday(), true
+		case dateCurrentWeek, dateLastWeek, dateCurrentMonth, dateLastMonth,
+			dateTheLastWeek, dateTheNextWeek, dateTheLastMonth, dateTheNextMonth:
+			return DateValue{period: v[0]}, true
+		}
+	case len(v) == 2 && v[0] == dateExact:
+		if ms, err := strconv.ParseInt(v[1], 10, 64); err == nil {
+			return ExactDate(time.UnixMilli(ms).UTC()), true
+		}
+		if t, err := time.ParseInLocation(formulaDateLayout, v[1], time.UTC); err == nil {
+			return ExactDate(t).Formula(), true
+		}
+	}
+	return DateValue{}, false
+}
+
+// dateFilter 生成日期字段的条件，不支持的比较会被转换为等价的条件组合
+func dateFilter(name, op string, d DateValue) (larkbitable.FilterInfo, error) {
+	cond := func(op string, d DateValue) larkbitable.FilterInfo {
+		fieldName, operator := name, op
+		return newConditionFilter(&larkbitable.Condition{FieldName: &fieldName, Operator: &operator, Value: d.values()})
+	}
+	unsupported := func() (larkbitable.FilterInfo, error) {
+		return larkbitable.FilterInfo{}, fmt.Errorf("%w: %s %s %s", ErrUnsupportedDateOperator, name, op, d)
+	}
+
+	if op == "isEmpty" || op == "isNotEmpty" {
+		fieldName, operator := name, op
+		return newConditionFilter(&larkbitable.Condition{FieldName: &fieldName, Operator: &operator, Value: []string{}}), nil
+	}
+
+	from, to := d, d
+	if d.end != nil {
+		from, to = d.start(), *d.end
+	}
+	if from.period != "" || to.period != "" {
+		// 时间段只能判断是否在其中
+		if op != "is" || d.end != nil {
+			return unsupported()
+		}
+		return cond(op, d), nil
+	}
+
+	switch op {
+	case "is":
+		if d.end == nil {
+			return cond(op, d), nil
+		}
+		return combineFilter(conjunctionAnd, cond("isGreater", from.shift(-1)), cond("isLess", to.shift(1)))
+	case "isNot":
+		return combineFilter(conjunctionOr, cond("isLess", from), cond("isGreater", to))
+	case "isGreater":
+		return cond(op, to), nil
+	case "isGreaterEqual":
+		return cond("isGreater", from.shift(-1)), nil
+	case "isLess":
+		return cond(op, from), nil
+	case "isLessEqual":
+		return cond("isLess", to.shift(1)), nil
+	}
+	return unsupported()
+}
+
+// buildCondition 生成单个条件，日期值会按日期字段的规则转换
+func buildCondition(name, op string, value interface{}) (larkbitable.FilterInfo, error) {
+	if d, ok := dateValueOf(value); ok {
+		return dateFilter(name, op, d)
+	}
+	encoded, err := encodeConditionValue(op, value)
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+	return newConditionFilter(&larkbitable.Condition{FieldName: &name, Operator: &op, Value: encoded}), nil
+}
@@ -0,0 +1,129 @@
+package biorm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDateConditions(t *testing.T) {
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	now := time.Date(2025, 1, 7, 0, 30, 0, 0, shanghai)
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	// 日期以 UTC 正午的时间戳传递
+	ms := func(days int) string {
+		return fmt.Sprintf(`"%d"`, time.Date(2025, 1, 7+days, 12, 0, 0, 0, time.UTC).UnixMilli())
+	}
+	db := NewDB(nil)
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "exact date keeps calendar day",
+			tx:   db.Where("日期 = ?", now),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"is","value":["ExactDate",` + ms(0) + `]}]}`,
+		},
+		{
+			name: "greater equal",
+			tx:   db.Where("日期 >= ?", now),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isGreater","value":["ExactDate",` + ms(-1) + `]}]}`,
+		},
+		{
+			name: "less equal today",
+			tx:   db.Where(F("日期").Lte(Today())),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isLess","value":["Tomorrow"]}]}`,
+		},
+		{
+			name: "greater equal yesterday",
+			tx:   db.Where(F("日期").Gte(Yesterday().In(shanghai))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isGreater","value":["ExactDate",` + ms(-2) + `]}]}`,
+		},
+		{
+			name: "last n days",
+			tx:   db.Where(F("日期").Eq(LastNDays(7).In(shanghai))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isGreater","value":["ExactDate",` + ms(-7) + `]},
+				{"field_name":"日期","operator":"isLess","value":["Tomorrow"]}]}`,
+		},
+		{
+			name: "last n days in default location",
+			tx:   db.Where(F("日期").Eq(LastNDays(7))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isGreater","value":["ExactDate",` + ms(-7) + `]},
+				{"field_name":"日期","operator":"isLess","value":["Tomorrow"]}]}`,
+		},
+		{
+			name: "between formula dates",
+			tx:   db.Where(F("计算日期").Between(ExactDate(now).Formula(), ExactDate(now.AddDate(0, 0, 3)).Formula())),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"计算日期","operator":"isGreater","value":["ExactDate","2025/01/06"]},
+				{"field_name":"计算日期","operator":"isLess","value":["ExactDate","2025/01/11"]}]}`,
+		},
+		{
+			name: "not today",
+			tx:   db.Where(F("日期").Ne(Today())),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"日期","operator":"isLess","value":["Today"]},
+				{"field_name":"日期","operator":"isGreater","value":["Today"]}]}`,
+		},
+		{
+			name: "negated today",
+			tx:   db.Where(Not(F("日期").IsToday())),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"日期","operator":"isLess","value":["Today"]},
+				{"field_name":"日期","operator":"isGreater","value":["Today"]}]}`,
+		},
+		{
+			name: "negated between",
+			tx:   db.Where("状态 = ?", "进行中").Where(Not(F("日期").Eq(DateBetween(now, now.AddDate(0, 0, 3))))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"日期","operator":"isLess","value":["ExactDate",` + ms(0) + `]},
+					{"field_name":"日期","operator":"isGreater","value":["ExactDate",` + ms(3) + `]}]}]}`,
+		},
+		{
+			name: "negated formula range and comparison",
+			tx: db.Not(F("计算日期").Between(ExactDate(now).Formula(), ExactDate(now.AddDate(0, 0, 3)).Formula())).
+				Not("日期 >= ?", now),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"日期","operator":"isLess","value":["ExactDate",` + ms(0) + `]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"计算日期","operator":"isLess","value":["ExactDate","2025/01/07"]},
+					{"field_name":"计算日期","operator":"isGreater","value":["ExactDate","2025/01/10"]}]}]}`,
+		},
+		{
+			name: "period",
+			tx:   db.Where("日期 = ?", CurrentWeek()).Or(F("日期").Eq(TheLastMonth())),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"日期","operator":"is","value":["CurrentWeek"]},
+				{"field_name":"日期","operator":"is","value":["TheLastMonth"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+
+	for _, tx := range []*DB{
+		db.Where("日期 >= ?", CurrentMonth()),
+		db.Where(F("日期").Contains(Today())),
+		db.Where(F("日期").Between(LastWeek(), Today())),
+		db.Not("日期 = ?", CurrentWeek()),
+	} {
+		if !errors.Is(tx.Error, ErrUnsupportedDateOperator) {
+			t.Errorf("err = %v, want ErrUnsupportedDateOperator", tx.Error)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package biorm
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	linkType = reflect.TypeOf(Link{})
+)
+
+// decodeRecords 将记录列表解码到 dest
+// dest 支持 *[]T、*[]*T、*T（取第一条记录），以及 *[]map[string]interface{}
+func decodeRecords(records []*larkbitable.AppTableRecord, dest interface{}) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w: %T", ErrInvalidDest, dest)
+	}
+	rv = rv.Elem()
+
+	switch rv.Kind() {
+	case reflect.Slice:
+		elemType := rv.Type().Elem()
+		isPtr := elemType.Kind() == reflect.Ptr
+		if isPtr {
+			elemType = elemType.Elem()
+		}
+
+		slice := reflect.MakeSlice(rv.Type(), 0, len(records))
+		for _, record := range records {
+			elem := reflect.New(elemType)
+			if err := decodeRecord(record, elem.Elem()); err != nil {
+				return err
+			}
+			if isPtr {
+				slice = reflect.Append(slice, elem)
+			} else {
+				slice = reflect.Append(slice, elem.Elem())
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Struct, reflect.Map:
+		if len(records) == 0 {
+			return nil
+		}
+		return decodeRecord(records[0], rv)
+	default:
+		return fmt.Errorf("%w: %T", ErrInvalidDest, dest)
+	}
+}
+
+// decodeRecord 将一条记录解码到结构体或 map 中
+func decodeRecord(record *larkbitable.AppTableRecord, v reflect.Value) error {
+	if record == nil {
+		return nil
+	}
+
+	if v.Kind() == reflect.Map {
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: %s", ErrInvalidDest, v.Type())
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMapWithSize(v.Type(), len(record.Fields)+1))
+		}
+		for name, raw := range record.Fields {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := decodeValue(raw, elem); err != nil {
+				return fmt.Errorf("字段 %s: %w", name, err)
+			}
+			v.SetMapIndex(reflect.ValueOf(name), elem)
+		}
+		if record.RecordId != nil && v.Type().Elem().Kind() == reflect.Interface {
+			v.SetMapIndex(reflect.ValueOf(RecordIdTag), reflect.ValueOf(*record.RecordId))
+		}
+		return nil
+	}
+
+	s, err := parseSchema(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, f := range s.Fields {
+		if f.IsRecordId {
+			if record.RecordId != nil {
+				f.fieldValue(v).SetString(*record.RecordId)
+			}
+			continue
+		}
+		raw, ok := record.Fields[f.Name]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, f.fieldValue(v)); err != nil {
+			return fmt.Errorf("字段 %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue 将多维表格返回的字段原始值解码到 v
+func decodeValue(raw interface{}, v reflect.Value) error {
+	raw = unwrapValue(raw)
+	if raw == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeValue(raw, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return fmt.Errorf("%w: 无法解码到 %s", ErrInvalidFieldValue, v.Type())
+		}
+		v.Set(reflect.ValueOf(raw))
+		return nil
+	case reflect.String:
+		v.SetString(textOf(raw))
+		return nil
+	case reflect.Bool:
+		b, err := boolOf(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, err := numberOf(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(f))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, err := numberOf(raw)
+		if err != nil {
+			return err
+		}
+		v.SetUint(uint64(f))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := numberOf(raw)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		return decodeSlice(raw, v)
+	case reflect.Struct:
+		switch v.Type() {
+		case timeType:
+			f, err := numberOf(raw)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(time.Unix(0, int64(f)*int64(time.Millisecond))))
+			return nil
+		case linkType:
+			v.Set(reflect.ValueOf(Link{RecordIds: linkRecordIds(raw)}))
+			return nil
+		}
+		// 人员等字段返回的是数组，解码到单个结构体时取第一个元素
+		if list, ok := raw.([]interface{}); ok {
+			if len(list) == 0 {
+				v.Set(reflect.Zero(v.Type()))
+				return nil
+			}
+			raw = list[0]
+		}
+		return decodeJSON(raw, v)
+	case reflect.Map:
+		return decodeJSON(raw, v)
+	default:
+		return fmt.Errorf("%w: 无法解码到 %s", ErrInvalidFieldValue, v.Type())
+	}
+}
+
+func decodeSlice(raw interface{}, v reflect.Value) error {
+	var list []interface{}
+	switch val := raw.(type) {
+	case []interface{}:
+		list = val
+	case map[string]interface{}:
+		if _, ok := val["link_record_ids"]; ok {
+			for _, id := range linkRecordIds(val) {
+				list = append(list, id)
+			}
+		} else {
+			list = []interface{}{val}
+		}
+	default:
+		list = []interface{}{val}
+	}
+
+	// 文本字段返回的是文本片段数组，解码到 []string 时合并为一个字符串
+	if isTextSegments(list) && v.Type().Elem().Kind() == reflect.String {
+		list = []interface{}{textOf(list)}
+	}
+
+	slice := reflect.MakeSlice(v.Type(), 0, len(list))
+	for _, item := range list {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(item, elem); err != nil {
+			return err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	v.Set(slice)
+	return nil
+}
+
+func decodeJSON(raw interface{}, v reflect.Value) error {
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFieldValue, err)
+	}
+	ptr := reflect.New(v.Type())
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFieldValue, err)
+	}
+	v.Set(ptr.Elem())
+	return nil
+}
+
+// unwrapValue 展开公式、查找引用字段返回的 {"type": x, "value": [...]} 结构
+func unwrapValue(raw interface{}) interface{} {
+	if m, ok := raw.(map[string]interface{}); ok && len(m) == 2 {
+		_, hasType := m["type"]
+		value, hasValue := m["value"]
+		if hasType && hasValue {
+			return unwrapValue(value)
+		}
+	}
+	return raw
+}
+
+// isTextSegments 判断是否为文本字段返回的文本片段数组
+func isTextSegments(list []interface{}) bool {
+	if len(list) == 0 {
+		return false
+	}
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if _, ok := m["text"]; !ok {
+			return false
+		}
+		if _, ok := m["type"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// textOf 将字段原始值转换为文本
+func textOf(raw interface{}) string {
+	switch val := unwrapValue(raw).(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	case []interface{}:
+		texts := make([]string, 0, len(val))
+		for _, item := range val {
+			texts = append(texts, textOf(item))
+		}
+		if isTextSegments(val) {
+			return strings.Join(texts, "")
+		}
+		return strings.Join(texts, ",")
+	case map[string]interface{}:
+		for _, key := range []string{"text", "name", "full_address"} {
+			if s, ok := val[key].(string); ok {
+				return s
+			}
+		}
+		if _, ok := val["link_record_ids"]; ok {
+			return strings.Join(linkRecordIds(val), ",")
+		}
+		b, _ := json.Marshal(val)
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+func numberOf(raw interface{}) (float64, error) {
+	switch val := raw.(type) {
+	case float64:
+		return val, nil
+	case string:
+		if val == "" {
+			return 0, nil
+		}
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q 不是数字", ErrInvalidFieldValue, val)
+		}
+		return f, nil
+	case bool:
+		if val {
+			return 1, nil
+		}
+		return 0, nil
+	case []interface{}:
+		if len(val) == 1 {
+			return numberOf(val[0])
+		}
+	}
+	return 0, fmt.Errorf("%w: %v 不是数字", ErrInvalidFieldValue, raw)
+}
+
+func boolOf(raw interface{}) (bool, error) {
+	switch val := raw.(type) {
+	case bool:
+		return val, nil
+	case float64:
+		return val != 0, nil
+	case string:
+		if val == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return false, fmt.Errorf("%w: %q 不是布尔值", ErrInvalidFieldValue, val)
+		}
+		return b, nil
+	case []interface{}:
+		if len(val) == 1 {
+			return boolOf(val[0])
+		}
+	}
+	return false, fmt.Errorf("%w: %v 不是布尔值", ErrInvalidFieldValue, raw)
+}
+
+// linkRecordIds 提取关联字段中的记录 ID
+// 查询接口返回 {"link_record_ids": [...]}，部分接口返回 [{"record_ids": [...]}]
+func linkRecordIds(raw interface{}) []string {
+	var ids []string
+	switch val := raw.(type) {
+	case map[string]interface{}:
+		for _, key := range []string{"link_record_ids", "record_ids"} {
+			if list, ok := val[key].([]interface{}); ok {
+				for _, id := range list {
+					if s, ok := id.(string); ok {
+						ids = append(ids, s)
+					}
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				ids = append(ids, s)
+			} else {
+				ids = append(ids, linkRecordIds(item)...)
+			}
+		}
+	case string:
+		ids = append(ids, val)
+	}
+	return ids
+}
@@ -0,0 +1,85 @@
+package biorm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+type decodeTask struct {
+	RecordId string    `biorm:"record_id"`
+	Title    string    `biorm:"任务名称"`
+	Amount   float64   `biorm:"金额"`
+	Count    int       `biorm:"数量"`
+	Done     bool      `biorm:"完成"`
+	Tags     []string  `biorm:"标签"`
+	Status   string    `biorm:"状态"`
+	Deadline time.Time `biorm:"截止日期"`
+	Owner    Person    `biorm:"负责人"`
+	Members  []Person  `biorm:"成员"`
+	Related  Link      `biorm:"关联任务"`
+	Formula  *int64    `biorm:"公式"`
+	Ignored  string    `biorm:"-"`
+	Untagged string
+}
+
+func TestDecodeRecords(t *testing.T) {
+	body := `{
+		"record_id": "rec1",
+		"fields": {
+			"任务名称": [{"type": "text", "text": "写"}, {"type": "text", "text": "文档"}],
+			"金额": 1.5,
+			"数量": 3,
+			"完成": true,
+			"标签": ["A", "B"],
+			"状态": "进行中",
+			"截止日期": 1736179200000,
+			"负责人": [{"id": "ou_1", "name": "张三"}],
+			"成员": [{"id": "ou_1", "name": "张三"}, {"id": "ou_2", "name": "李四"}],
+			"关联任务": {"link_record_ids": ["rec2", "rec3"]},
+			"公式": {"type": 2, "value": [42]},
+			"Untagged": "x"
+		}
+	}`
+	var record larkbitable.AppTableRecord
+	if err := json.Unmarshal([]byte(body), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	var tasks []*decodeTask
+	if err := decodeRecords([]*larkbitable.AppTableRecord{&record}, &tasks); err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("len(tasks) = %d, want 1", len(tasks))
+	}
+
+	formula := int64(42)
+	want := &decodeTask{
+		RecordId: "rec1",
+		Title:    "写文档",
+		Amount:   1.5,
+		Count:    3,
+		Done:     true,
+		Tags:     []string{"A", "B"},
+		Status:   "进行中",
+		Deadline: time.Unix(0, 1736179200000*int64(time.Millisecond)),
+		Owner:    Person{Id: "ou_1", Name: "张三"},
+		Members:  []Person{{Id: "ou_1", Name: "张三"}, {Id: "ou_2", Name: "李四"}},
+		Related:  Link{RecordIds: []string{"rec2", "rec3"}},
+		Formula:  &formula,
+	}
+	if !reflect.DeepEqual(tasks[0], want) {
+		t.Errorf("decode got %+v, want %+v", tasks[0], want)
+	}
+}
+
+func TestDecodeRecordsInvalidDest(t *testing.T) {
+	var tasks []decodeTask
+	if err := decodeRecords(nil, tasks); err == nil {
+		t.Error("expected error for non-pointer dest")
+	}
+}
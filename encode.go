@@ -0,0 +1,222 @@
+package biorm
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var (
+	personType     = reflect.TypeOf(Person{})
+	urlType        = reflect.TypeOf(Url{})
+	attachmentType = reflect.TypeOf(Attachment{})
+)
+
+// modelRecord 待写入的一条记录，model 用于回写记录 ID
+type modelRecord struct {
+	Fields map[string]interface{}
+	Model  reflect.Value // 结构体值，传入的是 map 时无效
+}
+
+// setRecordId 将记录 ID 回写到模型中
+func (r modelRecord) setRecordId(recordId string) {
+	if !r.Model.IsValid() || !r.Model.CanSet() {
+		return
+	}
+	s, err := parseSchema(r.Model.Type())
+	if err != nil || s.RecordIdField == nil {
+		return
+	}
+	s.RecordIdField.fieldValue(r.Model).SetString(recordId)
+}
+
+// recordId 读取模型中的记录 ID
+func (r modelRecord) recordId() string {
+	if !r.Model.IsValid() {
+		return ""
+	}
+	s, err := parseSchema(r.Model.Type())
+	if err != nil || s.RecordIdField == nil {
+		return ""
+	}
+	if v, ok := readField(r.Model, s.RecordIdField); ok {
+		return v.String()
+	}
+	return ""
+}
+
+// parseModelRecords 将 Create 等方法的入参展开为记录列表
+// 支持 map[string]interface{}、[]map[string]interface{}、结构体、结构体指针以及它们的切片
+func parseModelRecords(values ...interface{}) ([]modelRecord, error) {
+	records := make([]modelRecord, 0, len(values))
+	for _, value := range values {
+		switch val := value.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			records = append(records, modelRecord{Fields: val})
+			continue
+		case []map[string]interface{}:
+			for _, fields := range val {
+				records = append(records, modelRecord{Fields: fields})
+			}
+			continue
+		}
+
+		rv := reflect.ValueOf(value)
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return nil, fmt.Errorf("%w: %T", ErrInvalidDest, value)
+			}
+			rv = rv.Elem()
+		}
+
+		switch rv.Kind() {
+		case reflect.Struct:
+			record, err := encodeModel(rv, false)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				elem := rv.Index(i)
+				if elem.Kind() == reflect.Interface {
+					elem = elem.Elem()
+				}
+				sub, err := parseModelRecords(elem.Interface())
+				if err != nil {
+					return nil, err
+				}
+				// 通过切片下标回写记录 ID
+				if len(sub) == 1 && elem.Kind() == reflect.Struct && rv.Index(i).CanSet() {
+					sub[0].Model = rv.Index(i)
+				}
+				records = append(records, sub...)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedModel, value)
+		}
+	}
+	return records, nil
+}
+
+// encodeModel 将模型结构体编码为多维表格字段
+// 带有 readonly 的字段不会被写入；带有 omitempty 的字段或 skipZero 为 true 时零值字段不会被写入
+func encodeModel(v reflect.Value, skipZero bool) (modelRecord, error) {
+	s, err := parseSchema(v.Type())
+	if err != nil {
+		return modelRecord{}, err
+	}
+
+	fields := make(map[string]interface{}, len(s.Fields))
+	for _, f := range s.Fields {
+		if f.IsRecordId {
+			continue
+		}
+		if _, ok := f.Settings["readonly"]; ok {
+			continue
+		}
+
+		fv, ok := readField(v, f)
+		if !ok || fv.IsZero() {
+			if _, omit := f.Settings["omitempty"]; omit || skipZero {
+				continue
+			}
+		}
+		if !ok {
+			fields[f.Name] = nil
+			continue
+		}
+
+		value, err := encodeValue(fv)
+		if err != nil {
+			return modelRecord{}, fmt.Errorf("字段 %s: %w", f.Name, err)
+		}
+		fields[f.Name] = value
+	}
+	return modelRecord{Fields: fields, Model: v}, nil
+}
+
+// readField 读取结构体字段，嵌入的指针结构体为 nil 时返回 false
+func readField(v reflect.Value, f *schemaField) (reflect.Value, bool) {
+	for i, idx := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v, true
+}
+
+// encodeValue 将模型字段值编码为多维表格写入接口需要的结构
+func encodeValue(v reflect.Value) (interface{}, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return encodeValue(v.Elem())
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface(), nil
+	case reflect.Struct:
+		switch v.Type() {
+		case timeType:
+			t := v.Interface().(time.Time)
+			if t.IsZero() {
+				return nil, nil
+			}
+			return t.UnixNano() / int64(time.Millisecond), nil
+		case personType:
+			return []map[string]interface{}{{"id": v.Interface().(Person).Id}}, nil
+		case attachmentType:
+			return []map[string]interface{}{{"file_token": v.Interface().(Attachment).FileToken}}, nil
+		case linkType:
+			ids := v.Interface().(Link).RecordIds
+			if ids == nil {
+				ids = []string{}
+			}
+			return ids, nil
+		case urlType:
+			return v.Interface(), nil
+		}
+		return v.Interface(), nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		switch v.Type().Elem() {
+		case personType:
+			list := make([]map[string]interface{}, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				list = append(list, map[string]interface{}{"id": v.Index(i).Interface().(Person).Id})
+			}
+			return list, nil
+		case attachmentType:
+			list := make([]map[string]interface{}, 0, v.Len())
+			for i := 0; i < v.Len(); i++ {
+				list = append(list, map[string]interface{}{"file_token": v.Index(i).Interface().(Attachment).FileToken})
+			}
+			return list, nil
+		}
+		list := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := encodeValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, nil
+	case reflect.Map:
+		return v.Interface(), nil
+	default:
+		return nil, fmt.Errorf("%w: 无法编码 %s", ErrInvalidFieldValue, v.Type())
+	}
+}
@@ -0,0 +1,127 @@
+package biorm
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+type encodeTask struct {
+	RecordId  string    `biorm:"record_id"`
+	Title     string    `biorm:"任务名称"`
+	Tags      []string  `biorm:"标签"`
+	Deadline  time.Time `biorm:"截止日期"`
+	Owner     []Person  `biorm:"负责人"`
+	Related   Link      `biorm:"关联任务"`
+	Remark    string    `biorm:"备注;omitempty"`
+	Formula   float64   `biorm:"公式;readonly"`
+	CreatedAt time.Time `biorm:"创建时间;readonly"`
+}
+
+func TestParseModelRecords(t *testing.T) {
+	deadline := time.Unix(1736179200, 0)
+	tasks := []encodeTask{{
+		RecordId: "rec1",
+		Title:    "写文档",
+		Tags:     []string{"A", "B"},
+		Deadline: deadline,
+		Owner:    []Person{{Id: "ou_1", Name: "张三"}},
+		Related:  Link{RecordIds: []string{"rec2"}},
+		Formula:  1,
+	}}
+
+	records, err := parseModelRecords(&tasks, map[string]interface{}{"任务名称": "map"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %d, want 2", len(records))
+	}
+
+	want := map[string]interface{}{
+		"任务名称": "写文档",
+		"标签":   []interface{}{"A", "B"},
+		"截止日期": int64(1736179200000),
+		"负责人":  []map[string]interface{}{{"id": "ou_1"}},
+		"关联任务": []string{"rec2"},
+	}
+	if !reflect.DeepEqual(records[0].Fields, want) {
+		t.Errorf("fields got %#v, want %#v", records[0].Fields, want)
+	}
+	if got := records[0].recordId(); got != "rec1" {
+		t.Errorf("recordId() = %q, want rec1", got)
+	}
+
+	records[0].setRecordId("rec9")
+	if tasks[0].RecordId != "rec9" {
+		t.Errorf("record id not written back, got %q", tasks[0].RecordId)
+	}
+	if records[1].Model.IsValid() {
+		t.Error("map record should not have a model")
+	}
+}
+
+func TestCreateAndSaveWriteBackRecordId(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+
+	created := batchTask{Title: "写文档"}
+	if _, tx := db.Create(&created); tx.Error != nil || created.RecordId == "" {
+		t.Fatalf("Create record id = %q, err %v", created.RecordId, tx.Error)
+	}
+
+	saved := batchTask{Title: "评审"}
+	if tx := db.Save(&saved); tx.Error != nil || saved.RecordId == "" || saved.RecordId == created.RecordId {
+		t.Fatalf("Save record id = %q, err %v", saved.RecordId, tx.Error)
+	}
+
+	// 第二次 Save 使用回写的记录 ID 更新记录
+	srv.ResetRequests()
+	saved.Title = "评审（已完成）"
+	if tx := db.Save(&saved); tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	requests := srv.Requests()
+	if len(requests) != 1 || requests[0].Method != http.MethodPut || !strings.HasSuffix(requests[0].Path, "/records/"+saved.RecordId) {
+		t.Errorf("second Save requests = %+v, want one update", requests)
+	}
+	records := srv.Records(testAppToken, testTableId)
+	if len(records) != 2 || records[1].Fields["名称"] != "评审（已完成）" {
+		t.Errorf("records = %+v", records)
+	}
+
+	for _, value := range []interface{}{batchTask{Title: "值"}, map[string]interface{}{"名称": "map"}, []*batchTask{{}, {}}} {
+		if tx := db.Save(value); !errors.Is(tx.Error, ErrUnsupportedModel) {
+			t.Errorf("Save(%T) error = %v, want ErrUnsupportedModel", value, tx.Error)
+		}
+	}
+}
+
+func TestWritesReportCodeError(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	db := newBatchTestDB(srv)
+
+	checks := []struct {
+		name string
+		tx   *DB
+		code int
+	}{
+		{"Create", func() *DB { _, tx := db.Create(map[string]interface{}{"不存在": "a"}); return tx }(), biormtest.CodeFieldNameNotFound},
+		{"Update", func() *DB { _, tx := db.Update("recMissing", map[string]interface{}{"名称": "a"}); return tx }(), biormtest.CodeRecordNotFound},
+		{"Delete", func() *DB { _, tx := db.Delete("recMissing"); return tx }(), biormtest.CodeRecordNotFound},
+		{"Meta", func() *DB { _, tx := db.Base("appMissing").Meta(); return tx }(), biormtest.CodeAppNotFound},
+		{"Wiki", db.Wiki("nope"), biormtest.CodeWikiNodeNotFound},
+	}
+	for _, c := range checks {
+		if c.tx.Error == nil || c.tx.CodeError == nil || c.tx.CodeError.Code != c.code || c.tx.ApiResp == nil {
+			t.Errorf("%s error = %v, code error %v, want code %d", c.name, c.tx.Error, c.tx.CodeError, c.code)
+		}
+	}
+}
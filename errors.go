@@ -18,6 +18,9 @@ var (
 	// ErrRecordIdRequired RecordId必须提供
 	ErrRecordIdRequired = errors.New("recordId required")
 
+	// ErrRecordIdsRequired 批量获取记录时至少需要一个记录 ID
+	ErrRecordIdsRequired = errors.New("recordIds required")
+
 	// ErrParseAppTokenAndTableId 无法解析 appToken 和 tableId
 	ErrParseAppTokenAndTableId = errors.New("parse appToken and tableId failed")
 
@@ -26,4 +29,52 @@ var (
 
 	// ErrInvalidWhereParamsLength 长度不合法
 	ErrInvalidWhereParamsLength = errors.New("where condition params length is invalid")
+
+	// ErrFilterTooDeep 筛选条件的嵌套层级超过多维表格支持的两层
+	ErrFilterTooDeep = errors.New("filter nesting is too deep, bitable supports at most one level of condition groups")
+
+	// ErrOperatorNotNegatable 条件运算符无法取反
+	ErrOperatorNotNegatable = errors.New("operator can not be negated")
+
+	// ErrEmptyInValues in 条件没有任何值
+	ErrEmptyInValues = errors.New("in condition requires at least one value")
+
+	// ErrOrderRequired Last 需要通过 Order 指定排序
+	ErrOrderRequired = errors.New("order required")
+
+	// ErrMissingWhereClause 按条件更新、删除时没有设置任何条件
+	ErrMissingWhereClause = errors.New("WHERE conditions required")
+
+	// ErrInvalidDest 查询结果无法写入的目标类型
+	ErrInvalidDest = errors.New("dest must be a non-nil pointer to struct, slice or map")
+
+	// ErrUnsupportedModel 模型结构体定义不合法
+	ErrUnsupportedModel = errors.New("unsupported model")
+
+	// ErrInvalidFieldValue 字段值与模型字段类型不匹配
+	ErrInvalidFieldValue = errors.New("invalid field value")
+
+	// ErrTableNotFound 多维表格中没有指定名称的数据表
+	ErrTableNotFound = errors.New("table not found")
+
+	// ErrFieldNotFound 数据表中没有指定名称的字段
+	ErrFieldNotFound = errors.New("field not found")
+
+	// ErrFieldNameRequired 查询条件缺少字段名
+	ErrFieldNameRequired = errors.New("field name required")
+
+	// ErrUnsupportedDateOperator 日期字段不支持的条件
+	ErrUnsupportedDateOperator = errors.New("operator is not supported on date values")
+
+	// ErrInvalidOperator 字段类型不支持的条件运算符
+	ErrInvalidOperator = errors.New("operator is not valid for field type")
+
+	// ErrViewIdRequired ViewId必须提供
+	ErrViewIdRequired = errors.New("viewId required")
+
+	// ErrBaseUrlUnsupported 无法为 lark.Client 替换 Config.BaseUrl 指定的域名
+	ErrBaseUrlUnsupported = errors.New("lark client does not support BaseUrl override")
+
+	// ErrViewNotFound 数据表中没有指定名称的视图
+	ErrViewNotFound = errors.New("view not found")
 )
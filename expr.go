@@ -0,0 +1,193 @@
+package biorm
+
+import (
+	"fmt"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// FieldRef 字段引用，用于构建类型安全的查询条件，可以声明为常量复用
+// Usage:
+//
+//	const Age biorm.FieldRef = "年龄"
+//	db.Where(Age.Gte(18))
+type FieldRef string
+
+// F 返回指定名称的字段引用
+// Usage:
+//
+//	db.Where(biorm.F("年龄").Gte(18))
+//	db.Where(biorm.Or(biorm.F("标签").Contains("紧急"), biorm.F("负责人").IsEmpty()))
+func F(name string) FieldRef {
+	return FieldRef(name)
+}
+
+// Expr 由 FieldRef 的方法以及 And、Or、Not 构建的查询条件，可以传给 Where、Or、Not
+// 构建过程中的错误会在传给 Where 时返回
+type Expr struct {
+	filter larkbitable.FilterInfo
+	err    error
+}
+
+// Filter 返回条件对应的过滤器
+func (e Expr) Filter() (larkbitable.FilterInfo, error) {
+	return e.filter, e.err
+}
+
+// Eq 字段等于 value，value 为 []string 时表示多个值（例如多选字段的全部选项）
+func (f FieldRef) Eq(value interface{}) Expr {
+	return f.expr("is", value)
+}
+
+// Ne 字段不等于 value
+func (f FieldRef) Ne(value interface{}) Expr {
+	return f.expr("isNot", value)
+}
+
+// Gt 字段大于 value
+func (f FieldRef) Gt(value interface{}) Expr {
+	return f.expr("isGreater", value)
+}
+
+// Gte 字段大于等于 value
+func (f FieldRef) Gte(value interface{}) Expr {
+	return f.expr("isGreaterEqual", value)
+}
+
+// Lt 字段小于 value
+func (f FieldRef) Lt(value interface{}) Expr {
+	return f.expr("isLess", value)
+}
+
+// Lte 字段小于等于 value
+func (f FieldRef) Lte(value interface{}) Expr {
+	return f.expr("isLessEqual", value)
+}
+
+// Contains 字段包含 values，文本字段只能传入一个值
+func (f FieldRef) Contains(values ...interface{}) Expr {
+	if len(values) == 0 {
+		return Expr{err: fmt.Errorf("%w: %s contains", ErrInvalidWhereParamsLength, f)}
+	}
+	return f.expr("contains", values...)
+}
+
+// NotContains 字段不包含 values
+func (f FieldRef) NotContains(values ...interface{}) Expr {
+	if len(values) == 0 {
+		return Expr{err: fmt.Errorf("%w: %s doesNotContain", ErrInvalidWhereParamsLength, f)}
+	}
+	return f.expr("doesNotContain", values...)
+}
+
+// IsEmpty 字段为空
+func (f FieldRef) IsEmpty() Expr {
+	return f.expr("isEmpty")
+}
+
+// IsNotEmpty 字段不为空
+func (f FieldRef) IsNotEmpty() Expr {
+	return f.expr("isNotEmpty")
+}
+
+// In 字段等于 values 中的任意一个值
+func (f FieldRef) In(values ...interface{}) Expr {
+	if f == "" {
+		return Expr{err: ErrFieldNameRequired}
+	}
+	filter, err := inFilter(string(f), values)
+	return Expr{filter: filter, err: err}
+}
+
+// NotIn 字段不等于 values 中的任何一个值
+func (f FieldRef) NotIn(values ...interface{}) Expr {
+	return Not(f.In(values...))
+}
+
+// IsToday 日期字段为今天
+func (f FieldRef) IsToday() Expr {
+	return f.Eq(Today())
+}
+
+// IsYesterday 日期字段为昨天
+func (f FieldRef) IsYesterday() Expr {
+	return f.Eq(Yesterday())
+}
+
+// IsTomorrow 日期字段为明天
+func (f FieldRef) IsTomorrow() Expr {
+	return f.Eq(Tomorrow())
+}
+
+// Between 日期字段在 from 与 to 之间（包含两端），from、to 为 time.Time 或 DateValue
+// Usage:
+//
+//	db.Where(biorm.F("截止日期").Between(biorm.Today(), biorm.ExactDate(deadline)))
+func (f FieldRef) Between(from, to interface{}) Expr {
+	start, ok := dateValueOf(from)
+	end, ok2 := dateValueOf(to)
+	if !ok || !ok2 || start.end != nil || end.end != nil {
+		return Expr{err: fmt.Errorf("%w: %s between %v and %v", ErrUnsupportedDateOperator, f, from, to)}
+	}
+	start.end = &end
+	return f.Eq(start)
+}
+
+// expr 生成单个条件，多个 values 的编码结果会合并为条件的 value
+func (f FieldRef) expr(op string, values ...interface{}) Expr {
+	name := string(f)
+	if name == "" {
+		return Expr{err: ErrFieldNameRequired}
+	}
+
+	if len(values) == 1 {
+		filter, err := buildCondition(name, op, values[0])
+		if err != nil {
+			return Expr{err: fmt.Errorf("字段 %s：%w", name, err)}
+		}
+		return Expr{filter: filter}
+	}
+
+	cond := &larkbitable.Condition{FieldName: &name, Operator: &op, Value: []string{}}
+	for _, value := range values {
+		encoded, err := encodeConditionValue(op, value)
+		if err != nil {
+			return Expr{err: fmt.Errorf("字段 %s：%w", name, err)}
+		}
+		cond.Value = append(cond.Value, encoded...)
+	}
+	return Expr{filter: newConditionFilter(cond)}
+}
+
+// And 返回全部条件同时满足的条件
+func And(exprs ...Expr) Expr {
+	return combineExprs(conjunctionAnd, exprs)
+}
+
+// Or 返回满足任意一个条件的条件
+func Or(exprs ...Expr) Expr {
+	return combineExprs(conjunctionOr, exprs)
+}
+
+// Not 返回取反的条件
+func Not(expr Expr) Expr {
+	if expr.err != nil {
+		return expr
+	}
+	filter, err := negateFilter(expr.filter)
+	return Expr{filter: filter, err: err}
+}
+
+func combineExprs(conjunction string, exprs []Expr) Expr {
+	var filter larkbitable.FilterInfo
+	for _, e := range exprs {
+		if e.err != nil {
+			return e
+		}
+		var err error
+		if filter, err = combineFilter(conjunction, filter, e.filter); err != nil {
+			return Expr{err: err}
+		}
+	}
+	return Expr{filter: filter}
+}
@@ -0,0 +1,74 @@
+package biorm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExprWhere(t *testing.T) {
+	db := NewDB(nil)
+	const age FieldRef = "年龄"
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "comparison",
+			tx:   db.Where(age.Gte(18)).Where(F("年龄").Lt(60)),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"年龄","operator":"isGreaterEqual","value":["18"]},
+				{"field_name":"年龄","operator":"isLess","value":["60"]}]}`,
+		},
+		{
+			name: "contains and empty",
+			tx:   db.Where(Or(F("标签").Contains("a", "b"), F("负责人").IsEmpty())),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"标签","operator":"contains","value":["a","b"]},
+				{"field_name":"负责人","operator":"isEmpty","value":[]}]}`,
+		},
+		{
+			name: "grouped",
+			tx:   db.Where(And(F("状态").Eq("进行中"), Or(F("日期").IsToday(), F("日期").IsTomorrow()))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"日期","operator":"is","value":["Today"]},
+					{"field_name":"日期","operator":"is","value":["Tomorrow"]}]}]}`,
+		},
+		{
+			name: "not",
+			tx:   db.Where(Not(Or(F("a").Eq(1), F("b").Contains("x")))),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"a","operator":"isNot","value":["1"]},
+				{"field_name":"b","operator":"doesNotContain","value":["x"]}]}`,
+		},
+		{
+			name: "not in",
+			tx:   db.Not(F("优先级").In("P0", "P1")),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"优先级","operator":"isNot","value":["P0"]},
+				{"field_name":"优先级","operator":"isNot","value":["P1"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+}
+
+func TestExprErrors(t *testing.T) {
+	db := NewDB(nil)
+
+	if tx := db.Where(F("标签").Contains()); !errors.Is(tx.Error, ErrInvalidWhereParamsLength) {
+		t.Errorf("Contains() err = %v", tx.Error)
+	}
+	if tx := db.Where(And(F("a").Eq(1), F("").Eq(1))); !errors.Is(tx.Error, ErrFieldNameRequired) {
+		t.Errorf("empty field err = %v", tx.Error)
+	}
+	if tx := db.Where(F("a").In()); !errors.Is(tx.Error, ErrEmptyInValues) {
+		t.Errorf("In() err = %v", tx.Error)
+	}
+}
@@ -0,0 +1,556 @@
+package biorm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// 字段 ID 由 fld 前缀与 7 位字母、数字组成，例如 fldPTb0U2y；传入其它值时按字段名称查找
+const (
+	fieldIdPrefix = "fld"
+	fieldIdSuffix = 7
+)
+
+// isFieldId 判断是否为字段 ID，"fld" 开头的字段名称（例如 "fld备注"）按名称处理
+func isFieldId(s string) bool {
+	return hasIdShape(s, fieldIdPrefix, fieldIdSuffix, fieldIdSuffix)
+}
+
+// FieldType 多维表格的字段类型
+type FieldType int
+
+const (
+	FieldTypeText         FieldType = 1    // 多行文本
+	FieldTypeNumber       FieldType = 2    // 数字
+	FieldTypeSingleSelect FieldType = 3    // 单选
+	FieldTypeMultiSelect  FieldType = 4    // 多选
+	FieldTypeDateTime     FieldType = 5    // 日期
+	FieldTypeCheckbox     FieldType = 7    // 复选框
+	FieldTypeUser         FieldType = 11   // 人员
+	FieldTypePhone        FieldType = 13   // 电话号码
+	FieldTypeUrl          FieldType = 15   // 超链接
+	FieldTypeAttachment   FieldType = 17   // 附件
+	FieldTypeSingleLink   FieldType = 18   // 单向关联
+	FieldTypeLookup       FieldType = 19   // 查找引用
+	FieldTypeFormula      FieldType = 20   // 公式
+	FieldTypeDuplexLink   FieldType = 21   // 双向关联
+	FieldTypeLocation     FieldType = 22   // 地理位置
+	FieldTypeGroupChat    FieldType = 23   // 群组
+	FieldTypeCreatedTime  FieldType = 1001 // 创建时间
+	FieldTypeModifiedTime FieldType = 1002 // 最后更新时间
+	FieldTypeCreatedUser  FieldType = 1003 // 创建人
+	FieldTypeModifiedUser FieldType = 1004 // 修改人
+	FieldTypeAutoNumber   FieldType = 1005 // 自动编号
+)
+
+var fieldTypeNames = map[FieldType]string{
+	FieldTypeText:         "Text",
+	FieldTypeNumber:       "Number",
+	FieldTypeSingleSelect: "SingleSelect",
+	FieldTypeMultiSelect:  "MultiSelect",
+	FieldTypeDateTime:     "DateTime",
+	FieldTypeCheckbox:     "Checkbox",
+	FieldTypeUser:         "User",
+	FieldTypePhone:        "Phone",
+	FieldTypeUrl:          "Url",
+	FieldTypeAttachment:   "Attachment",
+	FieldTypeSingleLink:   "SingleLink",
+	FieldTypeLookup:       "Lookup",
+	FieldTypeFormula:      "Formula",
+	FieldTypeDuplexLink:   "DuplexLink",
+	FieldTypeLocation:     "Location",
+	FieldTypeGroupChat:    "GroupChat",
+	FieldTypeCreatedTime:  "CreatedTime",
+	FieldTypeModifiedTime: "ModifiedTime",
+	FieldTypeCreatedUser:  "CreatedUser",
+	FieldTypeModifiedUser: "ModifiedUser",
+	FieldTypeAutoNumber:   "AutoNumber",
+}
+
+// String 返回字段类型的名称，与 ui_type 的写法一致
+func (t FieldType) String() string {
+	if name, ok := fieldTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+// ParseFieldType 按名称解析字段类型，不区分大小写
+func ParseFieldType(name string) (FieldType, bool) {
+	for t, n := range fieldTypeNames {
+		if strings.EqualFold(n, name) {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// FieldOption 单选、多选字段的选项
+type FieldOption struct {
+	Id    string
+	Name  string
+	Color int
+}
+
+// Field 字段的元数据
+type Field struct {
+	Id          string
+	Name        string
+	Type        FieldType
+	UiType      string // 界面上的展示类型，例如进度、货币是数字的展示形态
+	IsPrimary   bool   // 是否为索引字段
+	IsHidden    bool
+	Description string
+
+	Options       []FieldOption // 单选、多选字段的选项
+	Formatter     string        // 数字、公式字段的显示格式，例如 "0.00"
+	DateFormatter string        // 日期字段的显示格式，例如 "yyyy/MM/dd"
+	Multiple      bool          // 人员、关联字段是否允许多个值
+	LinkTableId   string        // 关联字段关联的数据表 ID
+	LinkTableName string        // 关联字段关联的数据表名称
+
+	// 原始的字段属性，新增、更新字段时不为 nil 则直接使用
+	Property *larkbitable.AppTableFieldProperty
+}
+
+// OptionNames 返回单选、多选字段的全部选项名称
+func (f *Field) OptionNames() []string {
+	names := make([]string, 0, len(f.Options))
+	for _, o := range f.Options {
+		names = append(names, o.Name)
+	}
+	return names
+}
+
+// newField 将接口返回的字段转换为 Field
+func newField(name, id *string, typ *int, uiType *string, isPrimary, isHidden *bool, description interface{}, property *larkbitable.AppTableFieldProperty) *Field {
+	f := &Field{Property: property}
+	if name != nil {
+		f.Name = *name
+	}
+	if id != nil {
+		f.Id = *id
+	}
+	if typ != nil {
+		f.Type = FieldType(*typ)
+	}
+	if uiType != nil {
+		f.UiType = *uiType
+	}
+	f.IsPrimary = isPrimary != nil && *isPrimary
+	f.IsHidden = isHidden != nil && *isHidden
+
+	switch d := description.(type) {
+	case string:
+		f.Description = d
+	case *larkbitable.AppTableFieldDescription:
+		if d != nil && d.Text != nil {
+			f.Description = *d.Text
+		}
+	}
+
+	if property == nil {
+		return f
+	}
+	for _, o := range property.Options {
+		if o == nil {
+			continue
+		}
+		option := FieldOption{}
+		if o.Id != nil {
+			option.Id = *o.Id
+		}
+		if o.Name != nil {
+			option.Name = *o.Name
+		}
+		if o.Color != nil {
+			option.Color = *o.Color
+		}
+		f.Options = append(f.Options, option)
+	}
+	if property.Formatter != nil {
+		f.Formatter = *property.Formatter
+	}
+	if property.DateFormatter != nil {
+		f.DateFormatter = *property.DateFormatter
+	}
+	f.Multiple = property.Multiple != nil && *property.Multiple
+	if property.TableId != nil {
+		f.LinkTableId = *property.TableId
+	}
+	if property.TableName != nil {
+		f.LinkTableName = *property.TableName
+	}
+	return f
+}
+
+// build 转换为新增、更新字段接口的请求体
+func (f *Field) build() *larkbitable.AppTableField {
+	b := larkbitable.NewAppTableFieldBuilder().FieldName(f.Name).Type(int(f.Type))
+	if f.UiType != "" {
+		b.UiType(f.UiType)
+	}
+	if f.Description != "" {
+		b.Description(larkbitable.NewAppTableFieldDescriptionBuilder().Text(f.Description).Build())
+	}
+
+	property := f.Property
+	if property == nil {
+		property = &larkbitable.AppTableFieldProperty{}
+		empty := true
+		for _, o := range f.Options {
+			option := larkbitable.NewAppTableFieldPropertyOptionBuilder().Name(o.Name)
+			if o.Id != "" {
+				option.Id(o.Id)
+			}
+			if o.Color != 0 {
+				option.Color(o.Color)
+			}
+			property.Options = append(property.Options, option.Build())
+			empty = false
+		}
+		if f.Formatter != "" {
+			property.Formatter = &f.Formatter
+			empty = false
+		}
+		if f.DateFormatter != "" {
+			property.DateFormatter = &f.DateFormatter
+			empty = false
+		}
+		if f.Multiple {
+			multiple := true
+			property.Multiple = &multiple
+			empty = false
+		}
+		if f.LinkTableId != "" {
+			property.TableId = &f.LinkTableId
+			empty = false
+		}
+		if empty {
+			property = nil
+		}
+	}
+	if property != nil {
+		b.Property(property)
+	}
+	return b.Build()
+}
+
+func fieldCacheKey(appToken, tableId string) string {
+	return appToken + "/" + tableId
+}
+
+// cachedFields 返回缓存的字段列表，没有缓存时请求接口
+func (db *DB) cachedFields() ([]*Field, error) {
+	if db.cache != nil {
+		if fields, ok := db.cache.fields.Load(fieldCacheKey(db.AppToken, db.TableId)); ok {
+			return fields.([]*Field), nil
+		}
+	}
+	fields, tx := db.Fields()
+	if tx.hasError() {
+		db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+		return nil, tx.Error
+	}
+	return fields, nil
+}
+
+// uncacheFields 删除当前数据表的字段缓存
+func (db *DB) uncacheFields() {
+	if db.cache != nil {
+		db.cache.fields.Delete(fieldCacheKey(db.AppToken, db.TableId))
+	}
+}
+
+// resolveFieldId 按名称查找字段 ID，传入字段 ID 时直接返回；没有同名字段时按字段 ID 查找
+func (db *DB) resolveFieldId(nameOrId string) (string, error) {
+	if isFieldId(nameOrId) {
+		return nameOrId, nil
+	}
+	fields, err := db.cachedFields()
+	if err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		if f.Name == nameOrId {
+			return f.Id, nil
+		}
+	}
+	for _, f := range fields {
+		if f.Id == nameOrId {
+			return f.Id, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrFieldNotFound, nameOrId)
+}
+
+// validateFieldNames 检查 Select、Order 与查询条件中的字段是否存在于当前数据表
+func (db *DB) validateFieldNames() error {
+	fields, err := db.cachedFields()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.Name] = true
+	}
+
+	names := append([]string{}, db.Statement.Selects...)
+	for _, s := range db.Statement.Sort {
+		if s != nil && s.FieldName != nil {
+			names = append(names, *s.FieldName)
+		}
+	}
+	conditions := append([]*larkbitable.Condition{}, db.Statement.Filter.Conditions...)
+	for _, child := range db.Statement.Filter.Children {
+		if child != nil {
+			conditions = append(conditions, child.Conditions...)
+		}
+	}
+	for _, c := range conditions {
+		if c != nil && c.FieldName != nil {
+			names = append(names, *c.FieldName)
+		}
+	}
+
+	for _, name := range names {
+		if !known[name] {
+			return fmt.Errorf("%w: %s", ErrFieldNotFound, name)
+		}
+	}
+	return nil
+}
+
+// Fields 获取当前数据表的全部字段
+// Usage:
+//
+//	fields, tx := db.Base(appToken).Table(tableId).Fields()
+func (db *DB) Fields() (data []*Field, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	var pageToken string
+	for {
+		req := larkbitable.NewListAppTableFieldReqBuilder().
+			AppToken(tx.AppToken).TableId(tx.TableId).
+			PageToken(pageToken).
+			PageSize(100).
+			Build()
+
+		// 发起请求
+		var resp *larkbitable.ListAppTableFieldResp
+		err := tx.execute(apiCall{
+			Method:     http.MethodGet,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/fields",
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTableField.List(ctx, req)
+			return resp, err
+		})
+
+		// 处理错误
+		if !tx.checkResponse(resp, err) {
+			return
+		}
+		if resp.Data == nil {
+			break
+		}
+
+		for _, f := range resp.Data.Items {
+			if f != nil {
+				data = append(data, newField(f.FieldName, f.FieldId, f.Type, f.UiType, f.IsPrimary, f.IsHidden, f.Description, f.Property))
+			}
+		}
+
+		if resp.Data.HasMore == nil || !*resp.Data.HasMore || resp.Data.PageToken == nil || *resp.Data.PageToken == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+		if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+			tx.Error = err
+			return
+		}
+	}
+
+	if tx.cache != nil {
+		tx.cache.fields.Store(fieldCacheKey(tx.AppToken, tx.TableId), data)
+	}
+	return
+}
+
+// AddField 在当前数据表中新增字段
+// Usage:
+//
+//	field, tx := db.Base(appToken).Table(tableId).AddField(&biorm.Field{
+//		Name:    "优先级",
+//		Type:    biorm.FieldTypeSingleSelect,
+//		Options: []biorm.FieldOption{{Name: "P0"}, {Name: "P1"}},
+//	})
+func (db *DB) AddField(field *Field) (data *Field, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	clientToken := tx.clientTokenFor()
+	req := larkbitable.NewCreateAppTableFieldReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).
+		ClientToken(clientToken).
+		AppTableField(field.build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.CreateAppTableFieldResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodPost,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/fields",
+		Body:       req.AppTableField,
+		Idempotent: clientToken != "",
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableField.Create(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	tx.uncacheFields()
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil || resp.Data.Field == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+
+	f := resp.Data.Field
+	data = newField(f.FieldName, f.FieldId, f.Type, f.UiType, f.IsPrimary, f.IsHidden, f.Description, f.Property)
+	tx.RowsAffected = 1
+	return
+}
+
+// UpdateField 更新当前数据表中的字段，nameOrId 可以是字段名称或字段 ID
+// 接口会使用 field 覆盖字段的全部设置，需要同时传入字段名称与类型
+func (db *DB) UpdateField(nameOrId string, field *Field) (data *Field, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	fieldId, err := tx.resolveFieldId(nameOrId)
+	if err != nil {
+		tx.Error = err
+		return
+	}
+
+	req := larkbitable.NewUpdateAppTableFieldReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).FieldId(fieldId).
+		AppTableField(field.build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.UpdateAppTableFieldResp
+	err = tx.execute(apiCall{
+		Method:     http.MethodPut,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/fields/" + fieldId,
+		Body:       req.AppTableField,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableField.Update(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	tx.uncacheFields()
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil || resp.Data.Field == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+
+	f := resp.Data.Field
+	data = newField(f.FieldName, f.FieldId, f.Type, f.UiType, f.IsPrimary, f.IsHidden, f.Description, f.Property)
+	tx.RowsAffected = 1
+	return
+}
+
+// DropField 删除当前数据表中的字段，nameOrId 可以是字段名称或字段 ID
+func (db *DB) DropField(nameOrId string) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	fieldId, err := tx.resolveFieldId(nameOrId)
+	if err != nil {
+		tx.Error = err
+		return
+	}
+
+	req := larkbitable.NewDeleteAppTableFieldReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).FieldId(fieldId).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.DeleteAppTableFieldResp
+	err = tx.execute(apiCall{
+		Method:     http.MethodDelete,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/fields/" + fieldId,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableField.Delete(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	tx.uncacheFields()
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	tx.RowsAffected = 1
+	return
+}
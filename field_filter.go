@@ -0,0 +1,249 @@
+package biorm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// recordIdPrefix 记录 ID 的前缀
+const recordIdPrefix = "rec"
+
+// 各类字段支持的条件运算符
+var (
+	textOperators      = []string{"is", "isNot", "contains", "doesNotContain", "isEmpty", "isNotEmpty"}
+	numberOperators    = []string{"is", "isNot", "isGreater", "isGreaterEqual", "isLess", "isLessEqual", "isEmpty", "isNotEmpty"}
+	dateOperators      = []string{"is", "isGreater", "isLess", "isEmpty", "isNotEmpty"}
+	checkboxOperators  = []string{"is"}
+	emptyOnlyOperators = []string{"isEmpty", "isNotEmpty"}
+)
+
+// fieldOperators 字段类型支持的条件运算符，不在其中的类型（公式、查找引用等）不做检查
+var fieldOperators = map[FieldType][]string{
+	FieldTypeText:         textOperators,
+	FieldTypeNumber:       numberOperators,
+	FieldTypeSingleSelect: textOperators,
+	FieldTypeMultiSelect:  textOperators,
+	FieldTypeDateTime:     dateOperators,
+	FieldTypeCheckbox:     checkboxOperators,
+	FieldTypeUser:         textOperators,
+	FieldTypePhone:        textOperators,
+	FieldTypeUrl:          textOperators,
+	FieldTypeAttachment:   emptyOnlyOperators,
+	FieldTypeSingleLink:   textOperators,
+	FieldTypeDuplexLink:   textOperators,
+	FieldTypeLocation:     textOperators,
+	FieldTypeGroupChat:    textOperators,
+	FieldTypeCreatedTime:  dateOperators,
+	FieldTypeModifiedTime: dateOperators,
+	FieldTypeCreatedUser:  textOperators,
+	FieldTypeModifiedUser: textOperators,
+	FieldTypeAutoNumber:   textOperators,
+}
+
+// encodeFilterFields 按当前数据表的字段类型检查并编码查询条件
+func (db *DB) encodeFilterFields() error {
+	fields, err := db.cachedFields()
+	if err != nil {
+		return err
+	}
+	filter, err := encodeFilterByFields(db.Statement.Filter, fields)
+	if err != nil {
+		return err
+	}
+	db.Statement.Filter = filter
+	return nil
+}
+
+// fieldTypeLookup 开启 ValidateFields 且已指定数据表时，返回按名称查询字段类型的函数，否则返回 nil
+func (stmt *Statement) fieldTypeLookup() func(name string) FieldType {
+	db := stmt.DB
+	if db == nil || db.Config == nil || !db.Config.ValidateFields || db.AppToken == "" || db.TableId == "" {
+		return nil
+	}
+	return func(name string) FieldType {
+		fields, err := db.cachedFields()
+		if err != nil {
+			return 0
+		}
+		for _, f := range fields {
+			if f.Name == name {
+				return f.Type
+			}
+		}
+		return 0
+	}
+}
+
+// encodeFilterByFields 按字段类型检查并编码过滤器中每个条件的值，返回新的过滤器
+func encodeFilterByFields(f larkbitable.FilterInfo, fields []*Field) (larkbitable.FilterInfo, error) {
+	byName := make(map[string]*Field, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	result := copyFilter(f)
+	encode := func(conditions []*larkbitable.Condition) error {
+		for _, c := range conditions {
+			if c == nil || c.FieldName == nil || c.Operator == nil {
+				continue
+			}
+			field, ok := byName[*c.FieldName]
+			if !ok {
+				return fmt.Errorf("%w: %s", ErrFieldNotFound, *c.FieldName)
+			}
+			values, err := encodeFieldValue(field, *c.Operator, c.Value)
+			if err != nil {
+				return err
+			}
+			c.Value = values
+		}
+		return nil
+	}
+
+	if err := encode(result.Conditions); err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+	for _, child := range result.Children {
+		if child == nil {
+			continue
+		}
+		if err := encode(child.Conditions); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return result, nil
+}
+
+// encodeFieldValue 检查字段类型是否支持 op，并将条件的值转换为该类型字段要求的格式
+//
+//	数字：规范化数字文本，例如 1.500000 -> 1.5
+//	复选框：true 或 false
+//	单选、多选：选项名称，传入选项 ID 时转换为名称
+//	日期：ExactDate 与毫秒时间戳，支持传入时间戳以及 2006-01-02、2006/01/02 格式的日期
+//	人员：open_id 等用户 ID，不支持按姓名筛选
+//	关联：rec 开头的记录 ID
+func encodeFieldValue(field *Field, op string, values []string) ([]string, error) {
+	if ops, ok := fieldOperators[field.Type]; ok && !containsString(ops, op) {
+		return nil, fmt.Errorf("%w: %s 字段 %s 不支持 %s，支持 %s",
+			ErrInvalidOperator, field.Type, field.Name, op, strings.Join(ops, "、"))
+	}
+	if unaryOperators[op] {
+		return []string{}, nil
+	}
+	invalid := func(value string) error {
+		return fmt.Errorf("%w: %s 字段 %s 的值 %q", ErrInvalidFieldValue, field.Type, field.Name, value)
+	}
+
+	switch field.Type {
+	case FieldTypeNumber:
+		result := make([]string, 0, len(values))
+		for _, v := range values {
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, invalid(v)
+			}
+			result = append(result, strconv.FormatFloat(n, 'f', -1, 64))
+		}
+		return result, nil
+
+	case FieldTypeCheckbox:
+		if len(values) != 1 {
+			return nil, invalid(strings.Join(values, ","))
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(values[0]))
+		if err != nil {
+			return nil, invalid(values[0])
+		}
+		return []string{strconv.FormatBool(b)}, nil
+
+	case FieldTypeSingleSelect, FieldTypeMultiSelect:
+		if len(field.Options) == 0 {
+			return values, nil
+		}
+		result := make([]string, 0, len(values))
+		for _, v := range values {
+			name := ""
+			for _, o := range field.Options {
+				if o.Name == v || o.Id == v {
+					name = o.Name
+					break
+				}
+			}
+			if name == "" {
+				return nil, invalid(v)
+			}
+			result = append(result, name)
+		}
+		return result, nil
+
+	case FieldTypeDateTime, FieldTypeCreatedTime, FieldTypeModifiedTime:
+		if len(values) > 0 && isDateMode(values[0]) {
+			return values, nil
+		}
+		if len(values) != 1 {
+			return nil, invalid(strings.Join(values, ","))
+		}
+		v := strings.TrimSpace(values[0])
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return []string{dateExact, v}, nil
+		}
+		for _, layout := range []string{"2006-01-02", formulaDateLayout} {
+			if t, err := time.ParseInLocation(layout, v, DefaultLocation); err == nil {
+				return ExactDate(t).values(), nil
+			}
+		}
+		return nil, invalid(v)
+
+	case FieldTypeUser, FieldTypeCreatedUser, FieldTypeModifiedUser:
+		return encodeIdValues(values, "", invalid)
+
+	case FieldTypeSingleLink, FieldTypeDuplexLink:
+		return encodeIdValues(values, recordIdPrefix, invalid)
+	}
+	return values, nil
+}
+
+// encodeIdValues 去掉 ID 两端的空白，ID 需要以 prefix 开头并且只包含字母、数字、_ 与 -
+func encodeIdValues(values []string, prefix string, invalid func(string) error) ([]string, error) {
+	if len(values) == 0 {
+		return nil, invalid("")
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		id := strings.TrimSpace(v)
+		if len(id) <= len(prefix) || !strings.HasPrefix(id, prefix) {
+			return nil, invalid(v)
+		}
+		for _, c := range id {
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_' || c == '-') {
+				return nil, invalid(v)
+			}
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// isDateMode 判断是否为日期条件的取值类型，例如 ExactDate、Today
+func isDateMode(s string) bool {
+	switch s {
+	case dateExact, dateToday, dateTomorrow, dateYesterday,
+		dateCurrentWeek, dateLastWeek, dateCurrentMonth, dateLastMonth,
+		dateTheLastWeek, dateTheNextWeek, dateTheLastMonth, dateTheNextMonth:
+		return true
+	}
+	return false
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
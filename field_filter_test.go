@@ -0,0 +1,99 @@
+package biorm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeConditionValue(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+	}
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{1.5, []string{"1.5"}},
+		{float32(0.1), []string{"0.1"}},
+		{[]int{1, 2}, []string{"1", "2"}},
+		{Person{Id: "ou_1"}, []string{"ou_1"}},
+		{&Link{RecordIds: []string{"rec1", "rec2"}}, []string{"rec1", "rec2"}},
+	}
+	for _, tt := range tests {
+		got, err := encodeConditionValue("is", tt.value)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("encodeConditionValue(%#v) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+
+	for _, value := range []interface{}{point{X: 1}, &point{X: 1}, map[string]int{"a": 1}, []point{{X: 1}}} {
+		if _, err := encodeConditionValue("is", value); !errors.Is(err, ErrInvalidFieldValue) {
+			t.Errorf("encodeConditionValue(%#v) error = %v, want ErrInvalidFieldValue", value, err)
+		}
+	}
+}
+
+func TestEncodeFilterByFields(t *testing.T) {
+	fields := []*Field{
+		{Name: "价格", Type: FieldTypeNumber},
+		{Name: "完成", Type: FieldTypeCheckbox},
+		{Name: "状态", Type: FieldTypeSingleSelect, Options: []FieldOption{{Id: "opt1", Name: "进行中"}}},
+		{Name: "日期", Type: FieldTypeDateTime},
+		{Name: "附件", Type: FieldTypeAttachment},
+		{Name: "负责人", Type: FieldTypeUser},
+		{Name: "创建人", Type: FieldTypeCreatedUser},
+		{Name: "关联任务", Type: FieldTypeDuplexLink},
+	}
+	encode := func(tx *DB) (*DB, error) {
+		if tx.Error != nil {
+			return tx, tx.Error
+		}
+		f, err := encodeFilterByFields(tx.Statement.Filter, fields)
+		tx.Statement.Filter = f
+		return tx, err
+	}
+	db := NewDB(nil)
+
+	tx, err := encode(db.Where("价格 > ?", "1.500000").Where("完成 = ?", 1).Or("状态 = ?", "opt1").Or("日期 = ?", "1736251200000"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFilterBody(t, tx, `{"conjunction":"or","conditions":[
+		{"field_name":"状态","operator":"is","value":["进行中"]},
+		{"field_name":"日期","operator":"is","value":["ExactDate","1736251200000"]}],
+		"children":[{"conjunction":"and","conditions":[
+			{"field_name":"价格","operator":"isGreater","value":["1.5"]},
+			{"field_name":"完成","operator":"is","value":["true"]}]}]}`)
+
+	tx, err = encode(db.Where("负责人 contains ?", []string{" ou_1 ", "ou_2"}).Where(F("创建人").Eq(Person{Id: "ou_3"})).
+		Where(F("关联任务").Contains(&Link{RecordIds: []string{"recA1", "recB2"}})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertFilterBody(t, tx, `{"conjunction":"and","conditions":[
+		{"field_name":"负责人","operator":"contains","value":["ou_1","ou_2"]},
+		{"field_name":"创建人","operator":"is","value":["ou_3"]},
+		{"field_name":"关联任务","operator":"contains","value":["recA1","recB2"]}]}`)
+
+	errs := []struct {
+		tx   *DB
+		want error
+	}{
+		{db.Where("日期 contains ?", "2025"), ErrInvalidOperator},
+		{db.Where("附件 = ?", "x"), ErrInvalidOperator},
+		{db.Where("完成 contains ?", true), ErrInvalidOperator},
+		{db.Where("价格 = ?", "abc"), ErrInvalidFieldValue},
+		{db.Where("状态 = ?", "已取消"), ErrInvalidFieldValue},
+		{db.Where("日期 = ?", "下周"), ErrInvalidFieldValue},
+		{db.Where("负责人 = ?", "张三"), ErrInvalidFieldValue},
+		{db.Where(F("创建人").Eq(Person{Name: "张三"})), ErrInvalidFieldValue},
+		{db.Where("关联任务 contains ?", "任务A"), ErrInvalidFieldValue},
+		{db.Where("关联任务 = ?", "rec"), ErrInvalidFieldValue},
+	}
+	for _, e := range errs {
+		if _, err := encode(e.tx); !errors.Is(err, e.want) {
+			t.Errorf("err = %v, want %v", err, e.want)
+		}
+	}
+}
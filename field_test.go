@@ -0,0 +1,147 @@
+package biorm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+// newFieldServer 返回测试服务器，测试数据表中有名称（索引字段）、状态（单选）与价格（货币）三个字段
+func newFieldServer() *biormtest.Server {
+	srv := biormtest.NewServer()
+	srv.AddTable(testAppToken, testTableId, "任务")
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldName", Name: "名称", Type: 1, UiType: "Text", IsPrimary: true})
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldStatus", Name: "状态", Type: 3, UiType: "SingleSelect",
+		Property: map[string]interface{}{"options": []interface{}{
+			map[string]interface{}{"id": "opt1", "name": "进行中", "color": 1},
+			map[string]interface{}{"id": "opt2", "name": "已完成", "color": 2},
+		}}})
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldPrice", Name: "价格", Type: 2, UiType: "Currency",
+		Property: map[string]interface{}{"formatter": "0.00"}})
+	return srv
+}
+
+func TestFields(t *testing.T) {
+	srv := newFieldServer()
+	defer srv.Close()
+	table := newServerDB(srv).Base(testAppToken).Table(testTableId)
+
+	fields, tx := table.Fields()
+	if tx.Error != nil || len(fields) != 3 {
+		t.Fatalf("Fields() = %d fields, err %v", len(fields), tx.Error)
+	}
+	if f := fields[1]; f.Type != FieldTypeSingleSelect || strings.Join(f.OptionNames(), ",") != "进行中,已完成" {
+		t.Errorf("select field = %+v", f)
+	}
+	if f := fields[2]; f.UiType != "Currency" || f.Formatter != "0.00" || !fields[0].IsPrimary {
+		t.Errorf("number field = %+v", f)
+	}
+
+	added, tx := table.AddField(&Field{Name: "优先级", Type: FieldTypeSingleSelect, Options: []FieldOption{{Name: "P0"}}})
+	if tx.Error != nil || added.Id == "" || added.Options[0].Name != "P0" {
+		t.Fatalf("AddField = %+v, err %v", added, tx.Error)
+	}
+
+	updated, tx := table.UpdateField("优先级", &Field{Name: "紧急程度", Type: FieldTypeSingleSelect})
+	if tx.Error != nil || updated.Id != added.Id || updated.Name != "紧急程度" {
+		t.Fatalf("UpdateField = %+v, err %v", updated, tx.Error)
+	}
+
+	if tx := table.DropField("紧急程度"); tx.Error != nil || len(srv.Fields(testAppToken, testTableId)) != 3 {
+		t.Fatalf("DropField err %v, fields %v", tx.Error, srv.Fields(testAppToken, testTableId))
+	}
+	if tx := table.DropField("不存在"); !errors.Is(tx.Error, ErrFieldNotFound) {
+		t.Errorf("DropField(missing) error = %v, want ErrFieldNotFound", tx.Error)
+	}
+}
+
+func TestFieldIdOrName(t *testing.T) {
+	for s, want := range map[string]bool{
+		"fldPTb0U2y":  true,
+		"fld备注":       false,
+		"fldName":     false,
+		"fldPTb0U2y1": false,
+		"名称":          false,
+	} {
+		if got := isFieldId(s); got != want {
+			t.Errorf("isFieldId(%q) = %v, want %v", s, got, want)
+		}
+	}
+
+	srv := newFieldServer()
+	defer srv.Close()
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldRemark1", Name: "fld备注", Type: 1, UiType: "Text"})
+	table := newServerDB(srv).Base(testAppToken).Table(testTableId)
+
+	// 以 fld 开头的字段名称按名称查找
+	if updated, tx := table.UpdateField("fld备注", &Field{Name: "备注", Type: FieldTypeText}); tx.Error != nil || updated.Id != "fldRemark1" {
+		t.Fatalf("UpdateField(fld备注) = %+v, err %v", updated, tx.Error)
+	}
+	// 形状不符的字段 ID 在字段列表中按 ID 查找
+	if tx := table.DropField("fldPrice"); tx.Error != nil || len(srv.Fields(testAppToken, testTableId)) != 3 {
+		t.Errorf("DropField(fldPrice) err %v, fields %v", tx.Error, srv.Fields(testAppToken, testTableId))
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	srv := newFieldServer()
+	defer srv.Close()
+	db := newServerDB(srv)
+	db.Config.ValidateFields = true
+	table := db.Base(testAppToken).Table(testTableId)
+
+	if _, tx := table.Select("名称").Where("状态 = ?", "进行中").Order("价格").Records(); tx.Error != nil {
+		t.Fatalf("valid query error = %v", tx.Error)
+	}
+
+	srv.ResetRequests()
+	_, tx := table.Where(func(g *DB) *DB {
+		return g.Where("状态 = ?", "进行中").Or("负责人 isEmpty")
+	}).Records()
+	if !errors.Is(tx.Error, ErrFieldNotFound) || !strings.Contains(tx.Error.Error(), "负责人") {
+		t.Errorf("unknown field error = %v, want ErrFieldNotFound", tx.Error)
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Errorf("requests = %v, want fields served from cache and no search", requests)
+	}
+
+	if _, tx := table.Where("价格 contains ?", 1).Records(); !errors.Is(tx.Error, ErrInvalidOperator) {
+		t.Errorf("invalid operator error = %v, want ErrInvalidOperator", tx.Error)
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	srv := newFieldServer()
+	defer srv.Close()
+	db := newServerDB(srv)
+	db.Config.ValidateFields = true
+	table := db.Base(testAppToken).Table(testTableId)
+
+	if _, tx := table.Where("名称 = ?", "a").Records(); tx.Error != nil {
+		t.Fatalf("query error = %v", tx.Error)
+	}
+
+	// 在其它地方新增字段，缓存的字段列表中没有该字段
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldOwner", Name: "负责人", Type: 1, UiType: "Text"})
+	if _, tx := table.Where("负责人 = ?", "a").Records(); !errors.Is(tx.Error, ErrFieldNotFound) {
+		t.Fatalf("cached fields error = %v, want ErrFieldNotFound", tx.Error)
+	}
+
+	db.InvalidateCache()
+	srv.ResetRequests()
+	if _, tx := table.Where("负责人 = ?", "a").Records(); tx.Error != nil {
+		t.Fatalf("query after InvalidateCache error = %v", tx.Error)
+	}
+	var fields int
+	for _, r := range srv.Requests() {
+		if strings.HasSuffix(r.Path, "/fields") {
+			fields++
+		}
+	}
+	if fields != 1 {
+		t.Errorf("requests = %v, want fields requested again", srv.Requests())
+	}
+
+}
@@ -0,0 +1,255 @@
+package biorm
+
+import (
+	"fmt"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+const (
+	conjunctionAnd = "and"
+	conjunctionOr  = "or"
+)
+
+// negatedOperators 取反后的条件运算符，用于 Not 条件
+var negatedOperators = map[string]string{
+	"is":             "isNot",
+	"isNot":          "is",
+	"contains":       "doesNotContain",
+	"doesNotContain": "contains",
+	"isEmpty":        "isNotEmpty",
+	"isNotEmpty":     "isEmpty",
+	"isGreater":      "isLessEqual",
+	"isLessEqual":    "isGreater",
+	"isLess":         "isGreaterEqual",
+	"isGreaterEqual": "isLess",
+}
+
+// newConditionFilter 返回只包含一个条件的过滤器
+func newConditionFilter(cond *larkbitable.Condition) larkbitable.FilterInfo {
+	and := conjunctionAnd
+	return larkbitable.FilterInfo{Conjunction: &and, Conditions: []*larkbitable.Condition{cond}}
+}
+
+// filterLen 返回过滤器顶层的条件与条件组数量
+func filterLen(f larkbitable.FilterInfo) int {
+	return len(f.Conditions) + len(f.Children)
+}
+
+// filterConjunction 返回过滤器的连接词，未设置时为 and
+func filterConjunction(f larkbitable.FilterInfo) string {
+	if f.Conjunction == nil || *f.Conjunction == "" {
+		return conjunctionAnd
+	}
+	return *f.Conjunction
+}
+
+// combineFilter 使用 conjunction 连接两个过滤器，结果等价于 (left) conjunction (right)
+//
+// 多维表格的筛选条件最多只支持两层（顶层条件 + children 条件组），
+// 无法表示的嵌套会返回 ErrFilterTooDeep
+func combineFilter(conjunction string, left, right larkbitable.FilterInfo) (larkbitable.FilterInfo, error) {
+	if filterLen(right) == 0 {
+		return left, nil
+	}
+	if filterLen(left) == 0 {
+		result := copyFilter(right)
+		if filterLen(result) == 1 && len(result.Children) == 0 {
+			result.Conjunction = &conjunction
+		}
+		return result, nil
+	}
+
+	result := larkbitable.FilterInfo{Conjunction: &conjunction}
+	for _, f := range []larkbitable.FilterInfo{left, right} {
+		f = copyFilter(f)
+		if filterConjunction(f) == conjunction || filterLen(f) == 1 {
+			// 连接词相同，可以直接合并到同一层
+			result.Conditions = append(result.Conditions, f.Conditions...)
+			result.Children = append(result.Children, f.Children...)
+			continue
+		}
+		if len(f.Children) > 0 {
+			return larkbitable.FilterInfo{}, ErrFilterTooDeep
+		}
+		conj := filterConjunction(f)
+		result.Children = append(result.Children, &larkbitable.ChildrenFilter{
+			Conjunction: &conj,
+			Conditions:  f.Conditions,
+		})
+	}
+	return result, nil
+}
+
+// negateFilter 按德摩根定律对过滤器取反
+func negateFilter(f larkbitable.FilterInfo) (larkbitable.FilterInfo, error) {
+	conj := flipConjunction(filterConjunction(f))
+	result, err := negateConditions(conj, f.Conditions)
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+
+	for _, child := range f.Children {
+		if child == nil {
+			continue
+		}
+		childConj := conjunctionAnd
+		if child.Conjunction != nil {
+			childConj = *child.Conjunction
+		}
+		negated, err := negateConditions(flipConjunction(childConj), child.Conditions)
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if result, err = combineFilter(conj, result, negated); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return result, nil
+}
+
+// negateConditions 对每个条件取反后使用 conjunction 连接
+//
+// 日期条件通过 dateFilter 取反，>= 等日期不支持的运算符会被转换为等价的条件组合
+func negateConditions(conjunction string, conditions []*larkbitable.Condition) (larkbitable.FilterInfo, error) {
+	result := larkbitable.FilterInfo{Conjunction: &conjunction}
+	for _, cond := range conditions {
+		if cond == nil || cond.Operator == nil {
+			continue
+		}
+		op, ok := negatedOperators[*cond.Operator]
+		if !ok {
+			return larkbitable.FilterInfo{}, fmt.Errorf("%w: %s", ErrOperatorNotNegatable, *cond.Operator)
+		}
+
+		var negated larkbitable.FilterInfo
+		if d, ok := conditionDateValue(cond); ok && cond.FieldName != nil {
+			var err error
+			if negated, err = dateFilter(*cond.FieldName, op, d); err != nil {
+				return larkbitable.FilterInfo{}, err
+			}
+		} else {
+			newCond := copyCondition(cond)
+			newCond.Operator = &op
+			negated = newConditionFilter(newCond)
+		}
+
+		var err error
+		if result, err = combineFilter(conjunction, result, negated); err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+	}
+	return result, nil
+}
+
+func flipConjunction(conjunction string) string {
+	if conjunction == conjunctionOr {
+		return conjunctionAnd
+	}
+	return conjunctionOr
+}
+
+// copyFilter 深度复制过滤器
+func copyFilter(f larkbitable.FilterInfo) larkbitable.FilterInfo {
+	result := larkbitable.FilterInfo{}
+	if f.Conjunction != nil {
+		conj := *f.Conjunction
+		result.Conjunction = &conj
+	}
+	result.Conditions = copyConditions(f.Conditions)
+	if len(f.Children) > 0 {
+		result.Children = make([]*larkbitable.ChildrenFilter, 0, len(f.Children))
+		for _, child := range f.Children {
+			if child == nil {
+				continue
+			}
+			newChild := &larkbitable.ChildrenFilter{Conditions: copyConditions(child.Conditions)}
+			if child.Conjunction != nil {
+				conj := *child.Conjunction
+				newChild.Conjunction = &conj
+			}
+			result.Children = append(result.Children, newChild)
+		}
+	}
+	return result
+}
+
+func copyConditions(conditions []*larkbitable.Condition) []*larkbitable.Condition {
+	if len(conditions) == 0 {
+		return nil
+	}
+	result := make([]*larkbitable.Condition, 0, len(conditions))
+	for _, cond := range conditions {
+		if cond != nil {
+			result = append(result, copyCondition(cond))
+		}
+	}
+	return result
+}
+
+func copyCondition(cond *larkbitable.Condition) *larkbitable.Condition {
+	newCond := &larkbitable.Condition{}
+	if cond.FieldName != nil {
+		fieldName := *cond.FieldName
+		newCond.FieldName = &fieldName
+	}
+	if cond.Operator != nil {
+		operator := *cond.Operator
+		newCond.Operator = &operator
+	}
+	if cond.Value != nil {
+		newCond.Value = make([]string, len(cond.Value))
+		copy(newCond.Value, cond.Value)
+	}
+	return newCond
+}
+
+// buildConditionsBody 将条件转换为请求体中的结构
+func buildConditionsBody(conditions []*larkbitable.Condition) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(conditions))
+	for _, cond := range conditions {
+		if cond == nil || cond.FieldName == nil || cond.Operator == nil {
+			continue
+		}
+		value := cond.Value
+		if value == nil {
+			value = []string{}
+		}
+		result = append(result, map[string]interface{}{
+			"field_name": *cond.FieldName,
+			"operator":   *cond.Operator,
+			"value":      value,
+		})
+	}
+	return result
+}
+
+// buildFilterBody 将过滤器转换为查询记录接口请求体中的 filter，没有条件时返回 nil
+func buildFilterBody(f larkbitable.FilterInfo) map[string]interface{} {
+	if filterLen(f) == 0 {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"conjunction": filterConjunction(f),
+		"conditions":  buildConditionsBody(f.Conditions),
+	}
+	if len(f.Children) > 0 {
+		children := make([]map[string]interface{}, 0, len(f.Children))
+		for _, child := range f.Children {
+			if child == nil {
+				continue
+			}
+			conj := conjunctionAnd
+			if child.Conjunction != nil {
+				conj = *child.Conjunction
+			}
+			children = append(children, map[string]interface{}{
+				"conjunction": conj,
+				"conditions":  buildConditionsBody(child.Conditions),
+			})
+		}
+		body["children"] = children
+	}
+	return body
+}
@@ -0,0 +1,122 @@
+package biorm
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// assertFilterBody 断言查询请求体中的 filter 与期望的 JSON 一致
+func assertFilterBody(t *testing.T, tx *DB, want string) {
+	t.Helper()
+	if tx.Error != nil {
+		t.Fatalf("unexpected error: %v", tx.Error)
+	}
+
+	got, err := json.Marshal(tx.Statement.buildSearchBody()["filter"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("filter body\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestWhereOrGrouping(t *testing.T) {
+	db := NewDB(nil)
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "where and where",
+			tx:   db.Where("a = ?", "1").Where("b = ?", "2"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"a","operator":"is","value":["1"]},
+				{"field_name":"b","operator":"is","value":["2"]}]}`,
+		},
+		{
+			name: "where or",
+			tx:   db.Where("a = ?", "1").Or("b = ?", "2"),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"a","operator":"is","value":["1"]},
+				{"field_name":"b","operator":"is","value":["2"]}]}`,
+		},
+		{
+			name: "where or where",
+			tx:   db.Where("a = ?", "1").Or("b = ?", "2").Where("c = ?", "3"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"c","operator":"is","value":["3"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"a","operator":"is","value":["1"]},
+					{"field_name":"b","operator":"is","value":["2"]}]}]}`,
+		},
+		{
+			name: "where group",
+			tx: db.Where("a = ?", "1").Where(func(g *DB) *DB {
+				return g.Where("b = ?", "2").Or("c isEmpty")
+			}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"a","operator":"is","value":["1"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"b","operator":"is","value":["2"]},
+					{"field_name":"c","operator":"isEmpty","value":[]}]}]}`,
+		},
+		{
+			name: "or group",
+			tx: db.Where(func(g *DB) *DB {
+				return g.Where("a = ?", "1").Where("b = ?", "2")
+			}).Or(func(g *DB) *DB {
+				return g.Where("c = ?", "3").Where("d = ?", "4")
+			}),
+			want: `{"conjunction":"or","conditions":[],
+				"children":[
+					{"conjunction":"and","conditions":[
+						{"field_name":"a","operator":"is","value":["1"]},
+						{"field_name":"b","operator":"is","value":["2"]}]},
+					{"conjunction":"and","conditions":[
+						{"field_name":"c","operator":"is","value":["3"]},
+						{"field_name":"d","operator":"is","value":["4"]}]}]}`,
+		},
+		{
+			name: "not group",
+			tx: db.Where("a = ?", "1").Not(func(g *DB) *DB {
+				return g.Where("b > ?", 2).Or("c contains ?", "x")
+			}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"a","operator":"is","value":["1"]},
+				{"field_name":"b","operator":"isLessEqual","value":["2"]},
+				{"field_name":"c","operator":"doesNotContain","value":["x"]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+}
+
+func TestWhereFilterTooDeep(t *testing.T) {
+	tx := NewDB(nil).Where("a = ?", "1").Or("b = ?", "2").Where("c = ?", "3").Or("d = ?", "4")
+	if !errors.Is(tx.Error, ErrFilterTooDeep) {
+		t.Errorf("expected ErrFilterTooDeep, got %v", tx.Error)
+	}
+}
+
+func TestWhereDoesNotMutateParent(t *testing.T) {
+	base := NewDB(nil).Where("a = ?", "1")
+	_ = base.Or("b = ?", "2")
+	assertFilterBody(t, base, `{"conjunction":"and","conditions":[
+		{"field_name":"a","operator":"is","value":["1"]}]}`)
+}
@@ -2,186 +2,133 @@ package biorm
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"log"
 	"net/http"
-	"time"
+	"reflect"
+	"sync"
 
-	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
 )
 
-// Records 获取记录
+// Records 获取全部记录
+// 记录较多时请使用 Rows、Each 或 FindInBatches 按页处理，避免一次性加载到内存中
 func (db *DB) Records() (data []*larkbitable.AppTableRecord, tx *DB) {
-	tx = db.Clone()
+	rows, tx := db.Rows()
 	if tx.hasError() {
 		return
 	}
+	defer rows.Close()
 
-	if tx.AppToken == "" {
-		tx.Error = ErrAppTokenRequired
-		return
+	for rows.Next() {
+		data = append(data, rows.Record())
 	}
-	if tx.TableId == "" {
-		tx.Error = ErrTableIdRequired
+	return
+}
+
+// Find 查询记录并解码到 dest，字段通过 biorm tag 映射
+// Usage:
+//
+//	var tasks []Task
+//	tx := db.Base(appToken).Table(tableId).Where("状态 = ?", "进行中").Find(&tasks)
+func (db *DB) Find(dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
 		return
 	}
+	tx.Statement.Dest = dest
 
-	// 打印调试信息
-	log.Printf("条件调试 - AppToken: %s, TableId: %s", tx.AppToken, tx.TableId)
-	if tx.Statement.Filter.Conjunction != nil {
-		log.Printf("条件调试 - Conjunction: %s", *tx.Statement.Filter.Conjunction)
-	} else {
-		log.Printf("条件调试 - Conjunction: nil")
-	}
-	for i, cond := range tx.Statement.Filter.Conditions {
-		if cond != nil && cond.FieldName != nil && cond.Operator != nil {
-			log.Printf("条件调试 - 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
-				i, *cond.FieldName, *cond.Operator, cond.Value)
-		}
+	records, tx := tx.Records()
+	if tx.hasError() {
+		return
 	}
 
-	var pageToken string
-	for {
-		if pageToken != "" {
-			time.Sleep(tx.Config.RequestInterval)
-		}
-
-		//bodyBuilder := larkbitable.NewSearchAppTableRecordReqBodyBuilder().
-		//	AutomaticFields(tx.Statement.AutomaticFields)
+	if err := decodeRecords(records, dest); err != nil {
+		tx.Error = err
+	}
+	return
+}
 
-		body := make(map[string]interface{})
-		if tx.Statement.ViewId != "" {
-			//bodyBuilder.ViewId(tx.Statement.ViewId)
-			body["view_id"] = tx.Statement.ViewId
-		}
-		if tx.Statement.Selects != nil && len(tx.Statement.Selects) > 0 {
-			//	bodyBuilder.FieldNames(tx.Statement.Selects)
-			body["field_names"] = tx.Statement.Selects
-		}
-		if tx.Statement.Sort != nil && len(tx.Statement.Sort) > 0 {
-			//	bodyBuilder.Sort(tx.Statement.Sort)
-			body["sort"] = tx.Statement.Sort
-		}
-		if tx.Statement.Filter.Conjunction != nil && len(tx.Statement.Filter.Conditions) > 0 {
-			//	bodyBuilder.Filter(larkbitable.NewFilterInfoBuilder().
-			//		Conjunction(*tx.Statement.Filter.Conjunction).
-			//		Conditions(tx.Statement.Filter.Conditions).
-			//		Build(),
-			//	)
-			conditions := make([]map[string]interface{}, 0, len(tx.Statement.Filter.Conditions))
-			for i, condition := range tx.Statement.Filter.Conditions {
-				if condition == nil || condition.FieldName == nil || condition.Operator == nil {
-					log.Printf("条件调试 - 条件[%d]是无效的，跳过", i)
-					continue
-				}
+// First 按当前排序（Order 或视图的排序）查询第一条记录并解码到 dest，没有记录时返回 ErrRecordNotFound
+func (db *DB) First(dest interface{}) (tx *DB) {
+	return db.Limit(1).takeOne(dest)
+}
 
-				condMap := map[string]interface{}{
-					"field_name": *condition.FieldName,
-					"operator":   *condition.Operator,
-					"value":      condition.Value,
-				}
-				conditions = append(conditions, condMap)
-				log.Printf("条件调试 - 添加条件到请求: field_name=%s, operator=%s, value=%v",
-					*condition.FieldName, *condition.Operator, condition.Value)
-			}
+// Take 查询一条记录并解码到 dest，不使用 Order 指定的排序，没有记录时返回 ErrRecordNotFound
+func (db *DB) Take(dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.Sort = nil
+	return tx.Limit(1).takeOne(dest)
+}
 
-			if len(conditions) > 0 {
-				body["filter"] = map[string]interface{}{
-					"conjunction": *tx.Statement.Filter.Conjunction,
-					"conditions":  conditions,
-				}
-				log.Printf("条件调试 - 设置filter: conjunction=%s, conditions数量=%d",
-					*tx.Statement.Filter.Conjunction, len(conditions))
-
-				// 打印整个filter内容
-				filterJSON, _ := json.Marshal(body["filter"])
-				log.Printf("条件调试 - 完整filter: %s", string(filterJSON))
-			} else {
-				log.Printf("条件调试 - 没有有效条件，不设置filter")
-			}
-		} else {
-			log.Printf("条件调试 - Filter条件为空，Conjunction=%v, Conditions长度=%d",
-				tx.Statement.Filter.Conjunction, len(tx.Statement.Filter.Conditions))
-		}
-		body["automatic_fields"] = tx.Statement.AutomaticFields
-
-		//req := larkbitable.NewSearchAppTableRecordReqBuilder().
-		//	AppToken(tx.AppToken).TableId(tx.TableId).
-		//	UserIdType(tx.Statement.UserIdType).
-		//	PageToken(pageToken).
-		//	PageSize(500). // 分页大小。最大值为 500
-		//	Body(bodyBuilder.Build()).
-		//	Build()
-
-		// 发起请求
-		apiReq := larkcore.ApiReq{
-			HttpMethod: http.MethodPost,
-			ApiPath:    "https://open.feishu.cn/open-apis/bitable/v1/apps/:app_token/tables/:table_id/records/search",
-			Body:       body,
-			QueryParams: larkcore.QueryParams{
-				"user_id_type": []string{tx.Statement.UserIdType},
-				"page_token":   []string{pageToken},
-				"page_size":    []string{"500"},
-			},
-			PathParams: larkcore.PathParams{
-				"app_token": tx.AppToken,
-				"table_id":  tx.TableId,
-			},
-			SupportedAccessTokenTypes: []larkcore.AccessTokenType{larkcore.AccessTokenTypeTenant},
-		}
+// Last 按 Order 的相反顺序查询第一条记录并解码到 dest，没有记录时返回 ErrRecordNotFound
+// 没有使用 Order 时无法反转排序，返回 ErrOrderRequired
+func (db *DB) Last(dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	if len(tx.Statement.Sort) == 0 {
+		tx.Error = ErrOrderRequired
+		return
+	}
 
-		resp, err := tx.cli.Do(context.Background(), &apiReq)
-		// 处理错误
-		if err != nil {
-			tx.Error = err
-			if resp != nil {
-				tx.ApiResp = resp
-			}
-			return
-		}
-		if resp == nil {
-			tx.Error = ErrResponseIsNil
-			return
-		}
+	for _, s := range tx.Statement.Sort {
+		desc := s.Desc == nil || !*s.Desc
+		s.Desc = &desc
+	}
+	return tx.Limit(1).takeOne(dest)
+}
 
-		if resp.RawBody == nil {
-			tx.Error = fmt.Errorf("response body is nil: %w", ErrResponseIsNil)
-			return
-		}
+// takeOne 查询记录并将第一条解码到 dest
+func (db *DB) takeOne(dest interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	tx.Statement.Dest = dest
 
-		var response larkbitable.SearchAppTableRecordResp
-		err = json.Unmarshal(resp.RawBody, &response)
-		if err != nil {
-			tx.Error = fmt.Errorf("json unmarshal response body failed: %w", err)
-			return
-		}
-		if response.Data == nil {
-			tx.Error = fmt.Errorf("response data is nil: %w", ErrResponseIsNil)
-			tx.ApiResp = resp
-			return
-		}
+	records, tx := tx.Records()
+	if tx.hasError() {
+		return
+	}
+	if len(records) == 0 {
+		tx.Error = ErrRecordNotFound
+		return
+	}
 
-		for _, record := range response.Data.Items {
-			data = append(data, record)
-		}
+	if err := decodeRecords(records[:1], dest); err != nil {
+		tx.Error = err
+	}
+	return
+}
 
-		if !*response.Data.HasMore {
-			break
-		}
-		pageToken = *response.Data.PageToken
+// Count 查询满足条件的记录数，只请求一条记录并读取返回的 total
+func (db *DB) Count() (count int64, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
 	}
+	tx.Statement.PageSize = 1
+	tx.Statement.Limit = 1
+	tx.Statement.Offset = 0
+	tx.Statement.Sort = nil
 
-	// 清理无需保留的资源，帮助垃圾回收
-	tx.Finalize()
+	rows, tx := tx.Rows()
+	if tx.hasError() {
+		return
+	}
+	defer rows.Close()
 
-	return
+	if !rows.nextPage() && rows.Err() != nil {
+		return
+	}
+	return int64(rows.Total()), tx
 }
 
 // BatchGet 通过记录ID批量查询记录
-// recordIds 是记录ID的字符串数组。一次最多传入100个recordId，超出部分将会被忽略
+// recordIds 超过 MaxBatchGetSize 个时会自动分批请求，结果按 recordIds 的顺序返回。
+// 设置了 Config.Concurrency 时，多个批次会并发请求，每个批次仍间隔 RequestInterval 发出。
+// 部分记录不存在或无权限访问时，tx.Error 为 *MissingRecordsError，data 中仍包含查询到的记录
 func (db *DB) BatchGet(recordIds []string) (data []*larkbitable.AppTableRecord, tx *DB) {
 	tx = db.Clone()
 	if tx.hasError() {
@@ -197,90 +144,205 @@ func (db *DB) BatchGet(recordIds []string) (data []*larkbitable.AppTableRecord,
 		return
 	}
 	if len(recordIds) == 0 {
-		tx.Error = errors.New("recordIds cannot be empty")
+		tx.Error = ErrRecordIdsRequired
 		return
 	}
 
-	// 记录ID数量限制
-	if len(recordIds) > 100 {
-		log.Printf("警告: 批量查询的记录ID超过100个，将截取前100个")
-		recordIds = recordIds[:100]
+	ranges := chunkRanges(len(recordIds), MaxBatchGetSize)
+	results := make([]*larkbitable.BatchGetAppTableRecordRespData, len(ranges))
+	chunkErrs := make([]*ChunkError, len(ranges))
+
+	concurrency := tx.Config.Concurrency
+	if concurrency <= 1 {
+		for i, rg := range ranges {
+			if i > 0 {
+				if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+					tx.Error = err
+					return
+				}
+			}
+			results[i], chunkErrs[i] = tx.batchGetChunk(i, rg[0], recordIds[rg[0]:rg[1]])
+		}
+	} else {
+		// 每个批次的请求仍然间隔 RequestInterval 发出，context 取消后不再发起新的批次
+		var wg sync.WaitGroup
+		var err error
+		sem := make(chan struct{}, concurrency)
+		for i, rg := range ranges {
+			if i > 0 {
+				if err = sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+					break
+				}
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-tx.Statement.Context.Done():
+				err = tx.Statement.Context.Err()
+			}
+			if err != nil {
+				break
+			}
+
+			wg.Add(1)
+			go func(i int, rg [2]int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results[i], chunkErrs[i] = tx.batchGetChunk(i, rg[0], recordIds[rg[0]:rg[1]])
+			}(i, rg)
+		}
+		wg.Wait()
+		if err != nil {
+			tx.Error = err
+			return
+		}
 	}
 
-	// 构建请求体
-	body := map[string]interface{}{
-		"record_ids":       recordIds,
-		"user_id_type":     tx.Statement.UserIdType,
-		"automatic_fields": tx.Statement.AutomaticFields,
+	// 按输入顺序合并结果
+	batchErr := &BatchError{Total: len(ranges)}
+	missing := &MissingRecordsError{}
+	found := make(map[string]*larkbitable.AppTableRecord, len(recordIds))
+	for i, result := range results {
+		if chunkErrs[i] != nil {
+			batchErr.Chunks = append(batchErr.Chunks, chunkErrs[i])
+			continue
+		}
+		if result == nil {
+			continue
+		}
+		for _, record := range result.Records {
+			if record != nil && record.RecordId != nil {
+				found[*record.RecordId] = record
+			}
+		}
+		missing.AbsentRecordIds = append(missing.AbsentRecordIds, result.AbsentRecordIds...)
+		missing.ForbiddenRecordIds = append(missing.ForbiddenRecordIds, result.ForbiddenRecordIds...)
 	}
 
-	// 添加可选字段参数
-	if tx.Statement.Selects != nil && len(tx.Statement.Selects) > 0 {
-		body["field_names"] = tx.Statement.Selects
+	data = make([]*larkbitable.AppTableRecord, 0, len(found))
+	seen := make(map[string]bool, len(recordIds))
+	for _, id := range recordIds {
+		if record, ok := found[id]; ok && !seen[id] {
+			seen[id] = true
+			data = append(data, record)
+		}
 	}
 
-	// 添加视图参数
-	if tx.Statement.ViewId != "" {
-		body["view_id"] = tx.Statement.ViewId
+	// 清理无需保留的资源，帮助垃圾回收
+	tx.Finalize()
+
+	if len(batchErr.Chunks) > 0 {
+		tx.Error = batchErr
+		tx.ApiResp = batchErr.Chunks[0].ApiResp
+		tx.CodeError = batchErr.Chunks[0].CodeError
+	} else if len(missing.AbsentRecordIds) > 0 || len(missing.ForbiddenRecordIds) > 0 {
+		tx.Error = missing
 	}
+	return
+}
+
+// batchGetChunk 调用一次批量获取记录接口
+func (db *DB) batchGetChunk(index, offset int, recordIds []string) (*larkbitable.BatchGetAppTableRecordRespData, *ChunkError) {
+	chunkErr := &ChunkError{Index: index, Offset: offset, Size: len(recordIds), RecordIds: recordIds}
 
 	// 构建请求
 	req := larkbitable.NewBatchGetAppTableRecordReqBuilder().
-		AppToken(tx.AppToken).
-		TableId(tx.TableId).
+		AppToken(db.AppToken).
+		TableId(db.TableId).
 		Body(larkbitable.NewBatchGetAppTableRecordReqBodyBuilder().
 			RecordIds(recordIds).
-			UserIdType(tx.Statement.UserIdType).
+			UserIdType(db.Statement.UserIdType).
 			// WithSharedUrl(tx.Statement.WithSharedUrl).
-			AutomaticFields(tx.Statement.AutomaticFields).
+			AutomaticFields(db.Statement.AutomaticFields).
 			Build()).
 		Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.AppTableRecord.BatchGet(context.Background(), req)
+	var resp *larkbitable.BatchGetAppTableRecordResp
+	err := db.execute(apiCall{
+		Method:     http.MethodPost,
+		Path:       "/open-apis/bitable/v1/apps/" + db.AppToken + "/tables/" + db.TableId + "/records/batch_get",
+		Body:       req.Body,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = db.api().Bitable.V1.AppTableRecord.BatchGet(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
+	if err == nil && resp == nil {
+		err = ErrResponseIsNil
+	}
+	if resp != nil {
+		chunkErr.ApiResp = resp.ApiResp
+		chunkErr.CodeError = &resp.CodeError
+		if err == nil && !resp.Success() {
+			err = resp.CodeError
 		}
-		return
 	}
-	if resp == nil {
-		tx.Error = ErrResponseIsNil
-		return
+	if err == nil && resp.Data == nil {
+		err = fmt.Errorf("response data is nil: %w", ErrResponseIsNil)
+	}
+	if err != nil {
+		chunkErr.Err = err
+		return nil, chunkErr
 	}
+	return resp.Data, nil
+}
 
-	if resp.RawBody == nil {
-		tx.Error = fmt.Errorf("response body is nil: %w", ErrResponseIsNil)
+// Create inserts record, returning the inserted data's primary key in value's id
+// records 支持 map[string]interface{}、[]map[string]interface{}、带 biorm tag 的结构体指针及其切片，
+// 写入成功后会将记录 ID 回写到结构体的 `biorm:"record_id"` 字段
+// Usage:
+//
+//	db.Create(map[string]interface{}{"任务名称": "写文档"})
+//	db.Create(&task)
+//	db.Create(&tasks)
+func (db *DB) Create(records ...interface{}) (data []*larkbitable.AppTableRecord, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
 		return
 	}
 
-	var response larkbitable.BatchGetAppTableRecordResp
-	err = json.Unmarshal(resp.RawBody, &response)
-	if err != nil {
-		tx.Error = fmt.Errorf("json unmarshal response body failed: %w", err)
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
 		return
 	}
-	if response.Data == nil {
-		tx.Error = fmt.Errorf("response data is nil: %w", ErrResponseIsNil)
-		tx.ApiResp = resp.ApiResp
-		tx.CodeError = &resp.CodeError
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
 		return
 	}
 
-	data = response.Data.Records
+	list, err := parseModelRecords(records...)
+	if err != nil {
+		tx.Error = err
+		return
+	}
 
-	// 清理无需保留的资源，帮助垃圾回收
-	tx.Finalize()
+	if len(list) == 0 {
+		return
+	} else if len(list) == 1 {
+		var datum *larkbitable.AppTableRecord
+		datum, tx = tx.createSingle(list[0].Fields)
+		if tx.hasError() {
+			return
+		}
+		data = []*larkbitable.AppTableRecord{datum}
 
-	return
+		// 回写记录 ID
+		if datum != nil && datum.RecordId != nil {
+			list[0].setRecordId(*datum.RecordId)
+		}
+		tx.RowsAffected = 1
+		return
+	}
+	return tx.createInBatches(list, MaxBatchSize)
 }
 
-// Create inserts record, returning the inserted data's primary key in value's id
-func (db *DB) Create(records ...map[string]interface{}) (data []*larkbitable.AppTableRecord, tx *DB) {
+// CreateInBatches 按 batchSize 分批新增记录，batchSize 不合法时使用 MaxBatchSize
+// 设置了 Idempotent 时，每个批次会使用由 ClientToken 派生的固定 token，重试整个调用仍然是幂等的。
+// 部分批次失败时 tx.Error 为 *BatchError，data 中按输入顺序包含成功批次的结果
+func (db *DB) CreateInBatches(records interface{}, batchSize int) (data []*larkbitable.AppTableRecord, tx *DB) {
 	tx = db.getInstance()
 	if tx.hasError() {
 		return
@@ -295,19 +357,49 @@ func (db *DB) Create(records ...map[string]interface{}) (data []*larkbitable.App
 		return
 	}
 
-	if len(records) == 0 {
+	list, err := parseModelRecords(records)
+	if err != nil {
+		tx.Error = err
+		return
+	}
+	if batchSize <= 0 || batchSize > MaxBatchSize {
+		batchSize = MaxBatchSize
+	}
+	return tx.createInBatches(list, batchSize)
+}
+
+// Save 保存模型，记录 ID 为空时新增记录并回写记录 ID，否则更新对应记录
+// value 必须为结构体指针，否则返回 ErrUnsupportedModel
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).Save(&task)
+func (db *DB) Save(value interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
 		return
-	} else if len(records) == 1 {
-		var datum *larkbitable.AppTableRecord
-		datum, tx = tx.createSingle(records[0])
-		return []*larkbitable.AppTableRecord{datum}, tx
-	} else {
-		return tx.createInBatch(records)
 	}
+
+	list, err := parseModelRecords(value)
+	if err != nil {
+		tx.Error = err
+		return
+	}
+	if len(list) != 1 || !list[0].Model.IsValid() || !list[0].Model.CanSet() {
+		// 结构体值无法回写记录 ID，再次 Save 时会重复新增记录
+		tx.Error = fmt.Errorf("%w: Save 仅支持单个结构体指针", ErrUnsupportedModel)
+		return
+	}
+
+	if recordId := list[0].recordId(); recordId != "" {
+		_, tx = tx.Update(recordId, list[0].Fields)
+		return
+	}
+	_, tx = tx.Create(value)
+	return
 }
 
-// Update
-func (db *DB) Update(recordId string, fields map[string]interface{}) (data *larkbitable.UpdateAppTableRecordRespData, tx *DB) {
+// Update 更新记录，fields 支持 map[string]interface{} 或带 biorm tag 的结构体
+func (db *DB) Update(recordId string, fields interface{}) (data *larkbitable.UpdateAppTableRecordRespData, tx *DB) {
 	tx = db.getInstance()
 	if tx.hasError() {
 		return
@@ -326,36 +418,53 @@ func (db *DB) Update(recordId string, fields map[string]interface{}) (data *lark
 		return
 	}
 
+	list, err := parseModelRecords(fields)
+	if err != nil {
+		tx.Error = err
+		return
+	}
+	if len(list) != 1 {
+		tx.Error = fmt.Errorf("%w: Update 仅支持单条记录", ErrUnsupportedModel)
+		return
+	}
+
 	req := larkbitable.NewUpdateAppTableRecordReqBuilder().
 		AppToken(tx.AppToken).TableId(tx.TableId).RecordId(recordId).
 		UserIdType(tx.Statement.UserIdType).
 		AppTableRecord(larkbitable.NewAppTableRecordBuilder().
-			Fields(fields).
+			Fields(list[0].Fields).
 			Build()).
 		Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.AppTableRecord.Update(context.Background(), req)
+	var resp *larkbitable.UpdateAppTableRecordResp
+	err = tx.execute(apiCall{
+		Method:     http.MethodPut,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records/" + recordId,
+		Body:       req.AppTableRecord,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableRecord.Update(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
-		}
-		return
-	}
-	if resp == nil {
-		tx.Error = ErrResponseIsNil
+	if !tx.checkResponse(resp, err) {
 		return
 	}
 
 	return resp.Data, tx
 }
 
-// Delete
-func (db *DB) Delete(recordId string) (data *larkbitable.DeleteAppTableRecordRespData, tx *DB) {
+// Delete 删除记录
+// 传入一个记录 ID 时删除该记录，传入多个时分批删除，不传时删除满足 Where 条件的全部记录。
+// 没有任何条件时会返回 ErrMissingWhereClause，除非调用了 AllowGlobalUpdate 或设置了 Config.AllowGlobalUpdate
+// Usage:
+//
+//	db.Base(appToken).Table(tableId).Delete("recxxx")
+//	db.Base(appToken).Table(tableId).Where("状态 = ?", "过期").Delete()
+func (db *DB) Delete(recordIds ...string) (data *larkbitable.DeleteAppTableRecordRespData, tx *DB) {
 	tx = db.getInstance()
 	if tx.hasError() {
 		return
@@ -369,6 +478,22 @@ func (db *DB) Delete(recordId string) (data *larkbitable.DeleteAppTableRecordRes
 		tx.Error = ErrTableIdRequired
 		return
 	}
+
+	switch len(recordIds) {
+	case 0:
+		ids, tx := tx.matchedRecordIds()
+		if tx.hasError() || len(ids) == 0 {
+			return nil, tx
+		}
+		_, tx = tx.BatchDelete(ids)
+		return nil, tx
+	case 1:
+	default:
+		_, tx = tx.BatchDelete(recordIds)
+		return
+	}
+
+	recordId := recordIds[0]
 	if recordId == "" {
 		tx.Error = ErrRecordIdRequired
 		return
@@ -379,23 +504,108 @@ func (db *DB) Delete(recordId string) (data *larkbitable.DeleteAppTableRecordRes
 		Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.AppTableRecord.Delete(context.Background(), req)
+	var resp *larkbitable.DeleteAppTableRecordResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodDelete,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records/" + recordId,
+		Body:       nil,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableRecord.Delete(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+
+	if resp.Data != nil && resp.Data.Deleted != nil && *resp.Data.Deleted {
+		tx.RowsAffected = 1
+	}
+	return resp.Data, tx
+}
+
+// Updates 使用相同的字段值更新满足 Where 条件的全部记录，tx.RowsAffected 为更新成功的记录数
+// values 支持 map[string]interface{} 或带 biorm tag 的结构体，结构体只会更新非零值字段。
+// 没有任何条件时会返回 ErrMissingWhereClause，除非调用了 AllowGlobalUpdate 或设置了 Config.AllowGlobalUpdate
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).Where("状态 = ?", "过期").Updates(map[string]interface{}{"状态": "归档"})
+func (db *DB) Updates(values interface{}) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	var fields map[string]interface{}
+	if m, ok := values.(map[string]interface{}); ok {
+		fields = m
+	} else if rv := reflect.Indirect(reflect.ValueOf(values)); rv.Kind() == reflect.Struct {
+		record, err := encodeModel(rv, true)
+		if err != nil {
+			tx.Error = err
+			return
 		}
+		fields = record.Fields
+	} else {
+		tx.Error = fmt.Errorf("%w: %T", ErrUnsupportedModel, values)
 		return
 	}
-	if resp == nil {
-		tx.Error = ErrResponseIsNil
+	if len(fields) == 0 {
 		return
 	}
 
-	return resp.Data, tx
+	ids, tx := tx.matchedRecordIds()
+	if tx.hasError() || len(ids) == 0 {
+		return
+	}
+
+	records := make([]Record, 0, len(ids))
+	for _, id := range ids {
+		records = append(records, Record{RecordId: id, Fields: fields})
+	}
+	_, tx = tx.BatchUpdate(records)
+	return
+}
+
+// matchedRecordIds 查询满足当前条件的全部记录 ID，用于按条件更新、删除
+func (db *DB) matchedRecordIds() (ids []string, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if filterLen(tx.Statement.Filter) == 0 && !tx.Statement.AllowGlobalUpdate && !tx.Config.AllowGlobalUpdate {
+		tx.Error = ErrMissingWhereClause
+		return
+	}
+
+	records, found := tx.Records()
+	if found.hasError() {
+		tx.Error = found.Error
+		tx.ApiResp = found.ApiResp
+		tx.CodeError = found.CodeError
+		return
+	}
+
+	ids = make([]string, 0, len(records))
+	for _, record := range records {
+		if record != nil && record.RecordId != nil {
+			ids = append(ids, *record.RecordId)
+		}
+	}
+	return
 }
 
 func (db *DB) Meta() (data *larkbitable.GetAppRespData, tx *DB) {
@@ -413,19 +623,20 @@ func (db *DB) Meta() (data *larkbitable.GetAppRespData, tx *DB) {
 	req := larkbitable.NewGetAppReqBuilder().AppToken(tx.AppToken).Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.App.Get(context.Background(), req)
+	var resp *larkbitable.GetAppResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodGet,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken,
+		Body:       nil,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.App.Get(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
-		}
-		return
-	}
-	if resp == nil {
-		tx.Error = ErrResponseIsNil
+	if !tx.checkResponse(resp, err) {
 		return
 	}
 
@@ -448,28 +659,34 @@ func (db *DB) createSingle(fields map[string]interface{}) (data *larkbitable.App
 		return
 	}
 
+	clientToken := tx.clientTokenFor()
 	req := larkbitable.NewCreateAppTableRecordReqBuilder().
 		AppToken(tx.AppToken).TableId(tx.TableId).
 		UserIdType(tx.Statement.UserIdType).
-		ClientToken(tx.Statement.ClientToken).
+		ClientToken(clientToken).
 		AppTableRecord(larkbitable.NewAppTableRecordBuilder().
 			Fields(fields).
 			Build()).
 		Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.AppTableRecord.Create(context.Background(), req)
+	var resp *larkbitable.CreateAppTableRecordResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodPost,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records",
+		Body:       req.AppTableRecord,
+		Idempotent: clientToken != "",
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableRecord.Create(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
-		}
+	if !tx.checkResponse(resp, err) {
 		return
 	}
-	if resp == nil || resp.Data == nil {
+	if resp.Data == nil {
 		tx.Error = ErrResponseIsNil
 		return
 	}
@@ -477,7 +694,65 @@ func (db *DB) createSingle(fields map[string]interface{}) (data *larkbitable.App
 	return resp.Data.Record, tx
 }
 
-func (db *DB) createInBatch(records []map[string]interface{}) (data []*larkbitable.AppTableRecord, tx *DB) {
+// createInBatches 分批调用批量新增接口，并按输入顺序回写记录 ID
+func (db *DB) createInBatches(list []modelRecord, batchSize int) (data []*larkbitable.AppTableRecord, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	// 每个批次使用由同一个 token 派生的固定 token，重试时不会重复写入
+	clientToken := tx.clientTokenFor()
+	ranges := chunkRanges(len(list), batchSize)
+	batchErr := &BatchError{Total: len(ranges)}
+	for i, rg := range ranges {
+		if i > 0 {
+			if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+				tx.Error = err
+				return
+			}
+		}
+
+		chunk := list[rg[0]:rg[1]]
+		fields := make([]map[string]interface{}, 0, len(chunk))
+		for _, r := range chunk {
+			fields = append(fields, r.Fields)
+		}
+
+		created, chunkTx := tx.createInBatch(fields, chunkClientToken(clientToken, i))
+		if chunkTx.hasError() {
+			batchErr.Chunks = append(batchErr.Chunks, &ChunkError{
+				Index:     i,
+				Offset:    rg[0],
+				Size:      len(chunk),
+				Err:       chunkTx.Error,
+				ApiResp:   chunkTx.ApiResp,
+				CodeError: chunkTx.CodeError,
+			})
+			if ctxErr := tx.Statement.Context.Err(); ctxErr != nil {
+				break
+			}
+			continue
+		}
+
+		// 回写记录 ID
+		for j, datum := range created {
+			if j < len(chunk) && datum != nil && datum.RecordId != nil {
+				chunk[j].setRecordId(*datum.RecordId)
+			}
+		}
+		data = append(data, created...)
+		tx.RowsAffected += int64(len(created))
+	}
+
+	if len(batchErr.Chunks) > 0 {
+		tx.Error = batchErr
+	}
+	return
+}
+
+// createInBatch 调用一次批量新增接口
+func (db *DB) createInBatch(records []map[string]interface{}, clientToken string) (data []*larkbitable.AppTableRecord, tx *DB) {
 	tx = db.getInstance()
 	if tx.hasError() {
 		return
@@ -500,30 +775,29 @@ func (db *DB) createInBatch(records []map[string]interface{}) (data []*larkbitab
 	req := larkbitable.NewBatchCreateAppTableRecordReqBuilder().
 		AppToken(tx.AppToken).TableId(tx.TableId).
 		UserIdType(tx.Statement.UserIdType).
-		ClientToken(tx.Statement.ClientToken).
+		ClientToken(clientToken).
 		Body(larkbitable.NewBatchCreateAppTableRecordReqBodyBuilder().
 			Records(list).
 			Build()).
 		Build()
 
 	// 发起请求
-	resp, err := tx.cli.Bitable.V1.AppTableRecord.BatchCreate(context.Background(), req)
+	var resp *larkbitable.BatchCreateAppTableRecordResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodPost,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/records/batch_create",
+		Body:       req.Body,
+		Idempotent: clientToken != "",
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableRecord.BatchCreate(ctx, req)
+		return resp, err
+	})
 
 	// 处理错误
-	if err != nil {
-		tx.Error = err
-		if resp != nil {
-			tx.ApiResp = resp.ApiResp
-			tx.CodeError = &resp.CodeError
-		}
+	if !tx.checkResponse(resp, err) {
 		return
 	}
-	if resp == nil {
-		tx.Error = ErrResponseIsNil
-		return
-	}
-
-	// 业务处理
 	if resp.Data == nil {
 		tx.Error = ErrResponseIsNil
 		return
@@ -2,4 +2,4 @@ module github.com/2015WUJI01/biorm
 
 go 1.13
 
-require github.com/larksuite/oapi-sdk-go/v3 v3.4.12 // indirect
+require github.com/larksuite/oapi-sdk-go/v3 v3.4.12
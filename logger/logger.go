@@ -0,0 +1,152 @@
+// Package logger 定义 biorm 使用的日志接口及默认实现
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// LogLevel 日志级别
+type LogLevel int
+
+const (
+	// Silent 不输出任何日志
+	Silent LogLevel = iota + 1
+	// Error 只输出错误
+	Error
+	// Warn 输出错误与警告，包括慢请求
+	Warn
+	// Info 输出每一次 API 请求
+	Info
+	// Debug 输出条件构建等调试信息
+	Debug
+)
+
+// Writer 日志输出
+type Writer interface {
+	Printf(string, ...interface{})
+}
+
+// Config 日志配置
+type Config struct {
+	SlowThreshold time.Duration // 慢请求阈值，为 0 时不检查
+	LogLevel      LogLevel
+	TraceBody     bool // 是否输出请求体与响应体
+}
+
+// TraceInfo 一次 API 请求的信息
+type TraceInfo struct {
+	Method       string
+	Path         string
+	StatusCode   int
+	LogId        string
+	RequestBody  interface{}
+	ResponseBody []byte
+}
+
+// Interface 日志接口
+type Interface interface {
+	LogMode(LogLevel) Interface
+	Debug(context.Context, string, ...interface{})
+	Info(context.Context, string, ...interface{})
+	Warn(context.Context, string, ...interface{})
+	Error(context.Context, string, ...interface{})
+	Trace(ctx context.Context, begin time.Time, fc func() TraceInfo, err error)
+}
+
+var (
+	// Discard 丢弃全部日志
+	Discard = New(log.New(io.Discard, "", log.LstdFlags), Config{})
+	// Default 默认日志，输出警告及以上级别
+	Default = New(log.New(os.Stdout, "\r\n", log.LstdFlags), Config{
+		SlowThreshold: 3 * time.Second,
+		LogLevel:      Warn,
+	})
+)
+
+// New 使用 writer 创建日志
+func New(writer Writer, config Config) Interface {
+	return &logger{Writer: writer, Config: config}
+}
+
+type logger struct {
+	Writer
+	Config
+}
+
+// LogMode 返回指定级别的日志
+func (l *logger) LogMode(level LogLevel) Interface {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+func (l *logger) Debug(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Debug {
+		l.Printf("[debug] "+msg, data...)
+	}
+}
+
+func (l *logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Info {
+		l.Printf("[info] "+msg, data...)
+	}
+}
+
+func (l *logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Warn {
+		l.Printf("[warn] "+msg, data...)
+	}
+}
+
+func (l *logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Error {
+		l.Printf("[error] "+msg, data...)
+	}
+}
+
+// Trace 输出 API 请求日志：出错时为 Error，超过慢请求阈值时为 Warn，其余为 Info
+func (l *logger) Trace(ctx context.Context, begin time.Time, fc func() TraceInfo, err error) {
+	if l.LogLevel <= Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.LogLevel >= Error:
+		l.Printf("[error] %s %v", l.format(elapsed, fc()), err)
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= Warn:
+		l.Printf("[warn] SLOW REQUEST >= %v %s", l.SlowThreshold, l.format(elapsed, fc()))
+	case l.LogLevel >= Info:
+		l.Printf("[info] %s", l.format(elapsed, fc()))
+	}
+}
+
+func (l *logger) format(elapsed time.Duration, info TraceInfo) string {
+	s := fmt.Sprintf("[%.3fms] %s %s status=%d log_id=%s",
+		float64(elapsed.Nanoseconds())/1e6, info.Method, info.Path, info.StatusCode, info.LogId)
+	if l.TraceBody {
+		s += fmt.Sprintf("\nrequest: %s\nresponse: %s", FormatBody(info.RequestBody), string(info.ResponseBody))
+	}
+	return s
+}
+
+// FormatBody 将请求体格式化为 JSON 文本
+func FormatBody(body interface{}) string {
+	if body == nil {
+		return ""
+	}
+	if b, ok := body.([]byte); ok {
+		return string(b)
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Sprintf("%v", body)
+	}
+	return string(b)
+}
@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bufferWriter struct {
+	lines []string
+}
+
+func (w *bufferWriter) Printf(format string, args ...interface{}) {
+	w.lines = append(w.lines, fmt.Sprintf(format, args...))
+}
+
+func TestTrace(t *testing.T) {
+	info := func() TraceInfo {
+		return TraceInfo{Method: "POST", Path: "/records/search", StatusCode: 200, RequestBody: map[string]int{"a": 1}}
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		config Config
+		begin  time.Time
+		err    error
+		want   string
+	}{
+		{name: "silent", config: Config{LogLevel: Silent}, begin: time.Now(), err: errors.New("boom"), want: ""},
+		{name: "error", config: Config{LogLevel: Error}, begin: time.Now(), err: errors.New("boom"), want: "[error]"},
+		{name: "warn hides fast request", config: Config{LogLevel: Warn, SlowThreshold: time.Second}, begin: time.Now(), want: ""},
+		{name: "slow request", config: Config{LogLevel: Warn, SlowThreshold: time.Millisecond}, begin: time.Now().Add(-time.Second), want: "SLOW REQUEST"},
+		{name: "info", config: Config{LogLevel: Info}, begin: time.Now(), want: "[info]"},
+		{name: "trace body", config: Config{LogLevel: Info, TraceBody: true}, begin: time.Now(), want: `request: {"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bufferWriter{}
+			New(w, tt.config).Trace(ctx, tt.begin, info, tt.err)
+			got := strings.Join(w.lines, "\n")
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("expected no output, got %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("output %q does not contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogMode(t *testing.T) {
+	w := &bufferWriter{}
+	l := New(w, Config{LogLevel: Warn})
+	l.Debug(context.Background(), "hidden")
+	l.LogMode(Debug).Debug(context.Background(), "shown %d", 1)
+	if len(w.lines) != 1 || w.lines[0] != "[debug] shown 1" {
+		t.Errorf("unexpected output %q", w.lines)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// NewSlogLogger 使用 slog.Logger 输出日志，请求日志以结构化字段输出
+func NewSlogLogger(l *slog.Logger, config Config) Interface {
+	return &slogLogger{Logger: l, Config: config}
+}
+
+type slogLogger struct {
+	*slog.Logger
+	Config
+}
+
+func (l *slogLogger) LogMode(level LogLevel) Interface {
+	newLogger := *l
+	newLogger.LogLevel = level
+	return &newLogger
+}
+
+func (l *slogLogger) Debug(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Debug {
+		l.Logger.DebugContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Info {
+		l.Logger.InfoContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Warn {
+		l.Logger.WarnContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.LogLevel >= Error {
+		l.Logger.ErrorContext(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *slogLogger) Trace(ctx context.Context, begin time.Time, fc func() TraceInfo, err error) {
+	if l.LogLevel <= Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	attrs := func() []slog.Attr {
+		info := fc()
+		attrs := []slog.Attr{
+			slog.String("method", info.Method),
+			slog.String("path", info.Path),
+			slog.Int("status", info.StatusCode),
+			slog.String("log_id", info.LogId),
+			slog.Duration("elapsed", elapsed),
+		}
+		if l.TraceBody {
+			attrs = append(attrs,
+				slog.String("request_body", FormatBody(info.RequestBody)),
+				slog.String("response_body", string(info.ResponseBody)))
+		}
+		return attrs
+	}
+
+	switch {
+	case err != nil && l.LogLevel >= Error:
+		l.Logger.LogAttrs(ctx, slog.LevelError, "biorm request failed", append(attrs(), slog.Any("error", err))...)
+	case l.SlowThreshold != 0 && elapsed > l.SlowThreshold && l.LogLevel >= Warn:
+		l.Logger.LogAttrs(ctx, slog.LevelWarn, "biorm slow request", append(attrs(), slog.Duration("threshold", l.SlowThreshold))...)
+	case l.LogLevel >= Info:
+		l.Logger.LogAttrs(ctx, slog.LevelInfo, "biorm request", attrs()...)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	l := NewSlogLogger(slog.New(handler), Config{LogLevel: Info, TraceBody: true})
+	ctx := context.Background()
+	info := func() TraceInfo {
+		return TraceInfo{Method: "POST", Path: "/records/search", StatusCode: 200, LogId: "log1",
+			RequestBody: map[string]int{"a": 1}, ResponseBody: []byte(`{"code":0}`)}
+	}
+
+	l.Debug(ctx, "hidden")
+	l.Trace(ctx, time.Now(), info, nil)
+	l.Trace(ctx, time.Now(), info, errors.New("boom"))
+	l.LogMode(Debug).Debug(ctx, "shown %d", 1)
+	l.LogMode(Silent).Trace(ctx, time.Now(), info, errors.New("hidden"))
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid json line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("entries = %v, want 3", entries)
+	}
+
+	request := entries[0]
+	if request["level"] != "INFO" || request["msg"] != "biorm request" || request["method"] != "POST" ||
+		request["path"] != "/records/search" || request["status"] != float64(200) || request["log_id"] != "log1" ||
+		request["request_body"] != `{"a":1}` || request["response_body"] != `{"code":0}` {
+		t.Errorf("request entry = %v", request)
+	}
+	if failed := entries[1]; failed["level"] != "ERROR" || failed["msg"] != "biorm request failed" || failed["error"] != "boom" {
+		t.Errorf("error entry = %v", failed)
+	}
+	if debug := entries[2]; debug["level"] != "DEBUG" || debug["msg"] != "shown 1" {
+		t.Errorf("debug entry = %v", debug)
+	}
+}
@@ -0,0 +1,370 @@
+package biorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// Tabler 模型实现 TableName 时，AutoMigrate 使用其返回值作为数据表名称
+type Tabler interface {
+	TableName() string
+}
+
+// MigrateIssue AutoMigrate 发现但没有处理的字段问题
+type MigrateIssue struct {
+	Field  string
+	Reason string
+}
+
+func (i MigrateIssue) String() string {
+	return i.Field + ": " + i.Reason
+}
+
+// MigrateReport AutoMigrate 对一个模型的执行结果
+type MigrateReport struct {
+	Table        string // 数据表名称
+	TableId      string
+	CreatedTable bool           // 是否新建了数据表
+	AddedFields  []string       // 新增的字段
+	ExtraFields  []string       // 数据表中存在、模型中没有的字段，不会被删除
+	Issues       []MigrateIssue // 类型不兼容、选项缺失等需要手动处理的字段
+}
+
+// 各类 Go 类型可以读写的字段类型，第一个为新增字段时使用的类型
+var (
+	stringFieldTypes = []FieldType{FieldTypeText, FieldTypeSingleSelect, FieldTypePhone, FieldTypeUrl,
+		FieldTypeFormula, FieldTypeLookup, FieldTypeAutoNumber, FieldTypeLocation}
+	numberFieldTypes = []FieldType{FieldTypeNumber, FieldTypeFormula, FieldTypeLookup}
+	boolFieldTypes   = []FieldType{FieldTypeCheckbox, FieldTypeFormula, FieldTypeLookup}
+	timeFieldTypes   = []FieldType{FieldTypeDateTime, FieldTypeCreatedTime, FieldTypeModifiedTime,
+		FieldTypeFormula, FieldTypeLookup}
+	stringsFieldTypes = []FieldType{FieldTypeMultiSelect, FieldTypeLookup, FieldTypeFormula}
+	personFieldTypes  = []FieldType{FieldTypeUser, FieldTypeCreatedUser, FieldTypeModifiedUser, FieldTypeLookup}
+	urlFieldTypes     = []FieldType{FieldTypeUrl}
+	attachFieldTypes  = []FieldType{FieldTypeAttachment}
+	linkFieldTypes    = []FieldType{FieldTypeSingleLink, FieldTypeDuplexLink}
+)
+
+// inferFieldTypes 根据模型字段的 Go 类型推断可用的字段类型
+func inferFieldTypes(t reflect.Type) (types []FieldType, multiple bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return timeFieldTypes, false
+	case personType:
+		return personFieldTypes, false
+	case urlType:
+		return urlFieldTypes, false
+	case attachmentType:
+		return attachFieldTypes, false
+	case linkType:
+		return linkFieldTypes, true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return stringFieldTypes, false
+	case reflect.Bool:
+		return boolFieldTypes, false
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return numberFieldTypes, false
+	case reflect.Slice, reflect.Array:
+		elem := t.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		switch {
+		case elem == personType:
+			return personFieldTypes, true
+		case elem == attachmentType:
+			return attachFieldTypes, true
+		case elem.Kind() == reflect.String:
+			return stringsFieldTypes, true
+		}
+	}
+	return nil, false
+}
+
+// migrateField 模型字段期望的字段定义
+type migrateField struct {
+	field      *Field
+	compatible []FieldType // 已存在的字段可以接受的类型
+	err        error       // 无法确定字段定义的原因
+}
+
+// buildMigrateField 根据模型字段与 tag 生成字段定义
+// 支持的 tag 配置：type:SingleSelect、options:A,B、formatter:0.00、link:关联的数据表 ID、ui:Currency
+func buildMigrateField(sf *schemaField) migrateField {
+	f := &Field{Name: sf.Name, UiType: sf.Settings["ui"], Formatter: sf.Settings["formatter"]}
+	types, multiple := inferFieldTypes(sf.Type)
+
+	if name, ok := sf.Settings["type"]; ok {
+		t, ok := ParseFieldType(name)
+		if !ok {
+			return migrateField{field: f, err: fmt.Errorf("未知的字段类型 %s", name)}
+		}
+		types = []FieldType{t}
+	}
+	if len(types) == 0 {
+		return migrateField{field: f, err: fmt.Errorf("无法根据 Go 类型 %s 推断字段类型，请在 tag 中设置 type", sf.Type)}
+	}
+	f.Type = types[0]
+
+	if options, ok := sf.Settings["options"]; ok {
+		for _, name := range strings.Split(options, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				f.Options = append(f.Options, FieldOption{Name: name})
+			}
+		}
+	}
+	switch f.Type {
+	case FieldTypeUser:
+		f.Multiple = multiple
+	case FieldTypeSingleLink, FieldTypeDuplexLink:
+		f.Multiple = multiple
+		f.LinkTableId = sf.Settings["link"]
+		if f.LinkTableId == "" {
+			return migrateField{field: f, compatible: types, err: errors.New("关联字段需要在 tag 中设置 link:关联的数据表 ID")}
+		}
+	}
+	return migrateField{field: f, compatible: types}
+}
+
+// header 转换为新建数据表时的初始字段
+func (f *Field) header() *larkbitable.AppTableCreateHeader {
+	built := f.build()
+	return &larkbitable.AppTableCreateHeader{
+		FieldName:   built.FieldName,
+		Type:        built.Type,
+		UiType:      built.UiType,
+		Property:    built.Property,
+		Description: built.Description,
+	}
+}
+
+// modelTableName 返回模型对应的数据表名称
+func modelTableName(model interface{}, s *modelSchema) string {
+	if tabler, ok := model.(Tabler); ok {
+		return tabler.TableName()
+	}
+	if tabler, ok := reflect.New(s.Type).Interface().(Tabler); ok {
+		return tabler.TableName()
+	}
+	return s.Type.Name()
+}
+
+// AutoMigrate 根据模型结构体同步数据表结构
+// 数据表不存在时新建数据表；模型中有、数据表中没有的字段会被新增。
+// 数据表中多余的字段与类型不兼容的字段只会在返回的 MigrateReport 中列出，不会被修改或删除。
+// 数据表名称依次取自 Table() 选中的数据表（只传入一个模型时）、模型的 TableName() 方法以及结构体名称。
+// 新建数据表时，带有 primary 配置的字段作为索引字段，否则使用第一个字段。
+// Usage:
+//
+//	type Task struct {
+//		RecordId string   `biorm:"record_id"`
+//		Title    string   `biorm:"任务名称;primary"`
+//		Status   string   `biorm:"状态;type:SingleSelect;options:待办,进行中,已完成"`
+//		Tags     []string `biorm:"标签;options:前端,后端"`
+//	}
+//
+//	reports, tx := db.Base(appToken).AutoMigrate(&Task{})
+func (db *DB) AutoMigrate(models ...interface{}) (reports []*MigrateReport, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+
+	for i, model := range models {
+		if i > 0 {
+			if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+				tx.Error = err
+				return
+			}
+		}
+
+		s, err := parseSchema(reflect.TypeOf(model))
+		if err != nil {
+			tx.Error = err
+			return
+		}
+		report := &MigrateReport{Table: modelTableName(model, s)}
+		reports = append(reports, report)
+
+		table := tx.getInstance()
+		if len(models) == 1 && tx.TableId != "" {
+			report.TableId = tx.TableId
+		} else {
+			table.TableId = ""
+			report.TableId, err = table.resolveTableId(report.Table)
+			if err != nil && !errors.Is(err, ErrTableNotFound) {
+				tx.ApiResp, tx.CodeError = table.ApiResp, table.CodeError
+				tx.Error = err
+				return
+			}
+		}
+
+		var expected []migrateField
+		for _, sf := range s.Fields {
+			if sf.IsRecordId {
+				continue
+			}
+			expected = append(expected, buildMigrateField(sf))
+		}
+
+		if report.TableId == "" {
+			if err := table.createModelTable(report, s, expected); err != nil {
+				tx.Error = err
+				return
+			}
+			continue
+		}
+
+		table.TableId = report.TableId
+		if err := table.migrateFields(report, expected); err != nil {
+			tx.Error = err
+			return
+		}
+	}
+	return
+}
+
+// createModelTable 新建数据表，初始字段为模型中全部可以确定类型的字段
+func (db *DB) createModelTable(report *MigrateReport, s *modelSchema, expected []migrateField) error {
+	var headers []*larkbitable.AppTableCreateHeader
+	for _, mf := range expected {
+		if mf.err != nil {
+			report.Issues = append(report.Issues, MigrateIssue{Field: mf.field.Name, Reason: mf.err.Error()})
+			continue
+		}
+		header := mf.field.header()
+		if sf := s.FieldsByName[mf.field.Name]; sf != nil {
+			if _, ok := sf.Settings["primary"]; ok {
+				headers = append([]*larkbitable.AppTableCreateHeader{header}, headers...)
+				continue
+			}
+		}
+		headers = append(headers, header)
+	}
+
+	tableId, tx := db.CreateTable(report.Table, headers...)
+	if tx.hasError() {
+		db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+		return tx.Error
+	}
+	report.TableId = tableId
+	report.CreatedTable = true
+	for _, h := range headers {
+		report.AddedFields = append(report.AddedFields, *h.FieldName)
+	}
+	return nil
+}
+
+// migrateFields 对比数据表已有的字段，新增缺失的字段
+func (db *DB) migrateFields(report *MigrateReport, expected []migrateField) error {
+	fields, tx := db.Fields()
+	if tx.hasError() {
+		db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+		return tx.Error
+	}
+	existing := make(map[string]*Field, len(fields))
+	for _, f := range fields {
+		existing[f.Name] = f
+	}
+
+	declared := make(map[string]bool, len(expected))
+	added := 0
+	for _, mf := range expected {
+		name := mf.field.Name
+		declared[name] = true
+
+		if current, ok := existing[name]; ok {
+			report.Issues = append(report.Issues, compareField(mf, current)...)
+			continue
+		}
+		if mf.err != nil {
+			report.Issues = append(report.Issues, MigrateIssue{Field: name, Reason: mf.err.Error()})
+			continue
+		}
+
+		if added > 0 {
+			if err := sleepContext(db.Statement.Context, db.Config.RequestInterval); err != nil {
+				return err
+			}
+		}
+		if _, tx := db.AddField(mf.field); tx.hasError() {
+			db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+			return fmt.Errorf("新增字段 %s: %w", name, tx.Error)
+		}
+		added++
+		report.AddedFields = append(report.AddedFields, name)
+	}
+
+	for _, f := range fields {
+		if !declared[f.Name] {
+			report.ExtraFields = append(report.ExtraFields, f.Name)
+		}
+	}
+	return nil
+}
+
+// compareField 检查已存在的字段与模型字段是否兼容
+func compareField(mf migrateField, current *Field) []MigrateIssue {
+	if mf.err != nil && len(mf.compatible) == 0 {
+		// 无法确定模型需要的字段类型，例如 type 配置无法解析
+		return []MigrateIssue{{Field: current.Name, Reason: mf.err.Error()}}
+	}
+	compatible := false
+	for _, t := range mf.compatible {
+		if t == current.Type {
+			compatible = true
+			break
+		}
+	}
+	if !compatible {
+		names := make([]string, 0, len(mf.compatible))
+		for _, t := range mf.compatible {
+			names = append(names, t.String())
+		}
+		return []MigrateIssue{{
+			Field:  current.Name,
+			Reason: fmt.Sprintf("字段类型为 %s，模型需要 %s", current.Type, strings.Join(names, "/")),
+		}}
+	}
+
+	var issues []MigrateIssue
+	if mf.field.LinkTableId != "" && current.LinkTableId != "" && mf.field.LinkTableId != current.LinkTableId {
+		issues = append(issues, MigrateIssue{
+			Field:  current.Name,
+			Reason: fmt.Sprintf("关联的数据表为 %s，模型需要 %s", current.LinkTableId, mf.field.LinkTableId),
+		})
+	}
+	if len(mf.field.Options) > 0 && (current.Type == FieldTypeSingleSelect || current.Type == FieldTypeMultiSelect) {
+		known := make(map[string]bool, len(current.Options))
+		for _, o := range current.Options {
+			known[o.Name] = true
+		}
+		var missing []string
+		for _, o := range mf.field.Options {
+			if !known[o.Name] {
+				missing = append(missing, o.Name)
+			}
+		}
+		if len(missing) > 0 {
+			issues = append(issues, MigrateIssue{Field: current.Name, Reason: "缺少选项 " + strings.Join(missing, ",")})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,108 @@
+package biorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+type migrateTask struct {
+	RecordId string   `biorm:"record_id"`
+	Name     string   `biorm:"名称;primary"`
+	Status   string   `biorm:"状态;type:SingleSelect;options:进行中,已完成,已取消"`
+	Price    string   `biorm:"价格"`
+	Tags     []string `biorm:"标签;options:A,B"`
+	Parent   Link     `biorm:"父任务"`
+}
+
+func (migrateTask) TableName() string { return "任务" }
+
+type migrateProject struct {
+	Owner Person `biorm:"负责人"`
+	Title string `biorm:"项目名称;primary"`
+}
+
+func TestAutoMigrate(t *testing.T) {
+	srv := newFieldServer()
+	defer srv.Close()
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldNote", Name: "备注", Type: 1})
+
+	reports, tx := newServerDB(srv).Base(testAppToken).AutoMigrate(&migrateTask{}, migrateProject{})
+	if tx.Error != nil || len(reports) != 2 {
+		t.Fatalf("AutoMigrate = %v, err %v", reports, tx.Error)
+	}
+
+	task := reports[0]
+	if task.TableId != testTableId || task.CreatedTable || !reflect.DeepEqual(task.AddedFields, []string{"标签"}) {
+		t.Errorf("task report = %+v", task)
+	}
+	if !reflect.DeepEqual(task.ExtraFields, []string{"备注"}) {
+		t.Errorf("extra fields = %v, want [备注]", task.ExtraFields)
+	}
+	var issues []string
+	for _, issue := range task.Issues {
+		issues = append(issues, issue.String())
+	}
+	want := []string{
+		"状态: 缺少选项 已取消",
+		"价格: 字段类型为 Number，模型需要 Text/SingleSelect/Phone/Url/Formula/Lookup/AutoNumber/Location",
+		"父任务: 关联字段需要在 tag 中设置 link:关联的数据表 ID",
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q, want %q", issues, want)
+	}
+	fields := srv.Fields(testAppToken, testTableId)
+	if added := fields[len(fields)-1]; added.Name != "标签" || added.Type != int(FieldTypeMultiSelect) {
+		t.Errorf("added field = %v", added)
+	}
+
+	project := reports[1]
+	if !project.CreatedTable || tableName(srv, project.TableId) != "migrateProject" {
+		t.Errorf("project report = %+v, tables %v", project, srv.Tables(testAppToken))
+	}
+	if !reflect.DeepEqual(project.AddedFields, []string{"项目名称", "负责人"}) {
+		t.Errorf("project fields = %v, want primary field first", project.AddedFields)
+	}
+}
+
+type migrateLinked struct {
+	Name     string `biorm:"名称;primary"`
+	Parent   Link   `biorm:"父任务;link:tblsRc9GRRXKqhvW"`
+	Children Link   `biorm:"子任务;link:tbl0xe5g8PP3U3cS"`
+	Level    string `biorm:"优先级;type:Priority"`
+}
+
+func (migrateLinked) TableName() string { return "任务" }
+
+func TestAutoMigrateExistingFields(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	srv.AddTable(testAppToken, testTableId, "任务")
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Name: "名称", Type: 1, IsPrimary: true})
+	for _, name := range []string{"父任务", "子任务"} {
+		srv.AddField(testAppToken, testTableId, biormtest.Field{Name: name, Type: int(FieldTypeSingleLink),
+			Property: map[string]interface{}{"table_id": testTableId, "multiple": true}})
+	}
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Name: "优先级", Type: 1})
+
+	reports, tx := newServerDB(srv).Base(testAppToken).AutoMigrate(&migrateLinked{})
+	if tx.Error != nil || len(reports) != 1 {
+		t.Fatalf("AutoMigrate = %v, err %v", reports, tx.Error)
+	}
+	report := reports[0]
+	if report.TableId != testTableId || len(report.AddedFields) != 0 || len(report.ExtraFields) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	var issues []string
+	for _, issue := range report.Issues {
+		issues = append(issues, issue.String())
+	}
+	want := []string{
+		"父任务: 关联的数据表为 tbl0xe5g8PP3U3cS，模型需要 tblsRc9GRRXKqhvW",
+		"优先级: 未知的字段类型 Priority",
+	}
+	if !reflect.DeepEqual(issues, want) {
+		t.Errorf("issues = %q, want %q", issues, want)
+	}
+}
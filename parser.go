@@ -0,0 +1,559 @@
+package biorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// NamedArg 命名参数，用于绑定查询条件中的 @name 占位符
+type NamedArg struct {
+	Name  string
+	Value interface{}
+}
+
+// Named 创建一个命名参数
+// Usage:
+//
+//	db.Where("金额 >= @amount", biorm.Named("amount", 100))
+func Named(name string, value interface{}) NamedArg {
+	return NamedArg{Name: name, Value: value}
+}
+
+// ParseError 查询条件解析错误
+type ParseError struct {
+	Query  string // 原始查询条件
+	Column int    // 出错位置，从 1 开始按字符计数
+	Msg    string // 错误描述
+	Err    error  // 错误类别，例如 ErrInvalidWhereParamsLength
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("解析查询条件失败（第 %d 列）：%s：%s", e.Column, e.Msg, e.Query)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+type tokenKind int
+
+const (
+	tokenEOF         tokenKind = iota
+	tokenWord                  // 字段名、运算符、关键字或不带引号的值
+	tokenIdent                 // 反引号包裹的字段名
+	tokenString                // 单引号或双引号包裹的字符串
+	tokenSymbol                // =、!=、<>、>、>=、<、<=
+	tokenLParen                // (
+	tokenRParen                // )
+	tokenPlaceholder           // ?
+	tokenNamed                 // @name
+	tokenComma                 // ,
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 从 0 开始的字符位置
+}
+
+// tokenize 将查询条件拆分为 token 列表
+func tokenize(query string) ([]token, error) {
+	runes := []rune(query)
+	tokens := make([]token, 0)
+	isDelimiter := func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("(),=<>!?'\"`@", r)
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '?':
+			tokens = append(tokens, token{kind: tokenPlaceholder, text: "?", pos: i})
+			i++
+		case r == '@':
+			start := i
+			i++
+			for i < len(runes) && !isDelimiter(runes[i]) {
+				i++
+			}
+			if i == start+1 {
+				return nil, &ParseError{Query: query, Column: start + 1, Msg: "命名参数缺少名称"}
+			}
+			tokens = append(tokens, token{kind: tokenNamed, text: string(runes[start+1 : i]), pos: start})
+		case r == '\'' || r == '"' || r == '`':
+			start := i
+			quote := r
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) && quote != '`' {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					// 连续两个引号表示引号本身
+					if i+1 < len(runes) && runes[i+1] == quote {
+						sb.WriteRune(quote)
+						i += 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &ParseError{Query: query, Column: start + 1, Msg: "引号未闭合"}
+			}
+			kind := tokenString
+			if quote == '`' {
+				kind = tokenIdent
+			}
+			tokens = append(tokens, token{kind: kind, text: sb.String(), pos: start})
+		case r == '=' || r == '<' || r == '>' || r == '!':
+			start := i
+			i++
+			if i < len(runes) && (runes[i] == '=' || (r == '<' && runes[i] == '>')) {
+				i++
+			}
+			text := string(runes[start:i])
+			if text == "!" {
+				return nil, &ParseError{Query: query, Column: start + 1, Msg: "无法识别的符号 !"}
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: text, pos: start})
+		default:
+			start := i
+			for i < len(runes) && !isDelimiter(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: start})
+		}
+	}
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(runes)})
+	return tokens, nil
+}
+
+// symbolOperators 符号运算符与多维表格运算符的对应关系
+var symbolOperators = map[string]string{
+	"=":  "is",
+	"!=": "isNot",
+	"<>": "isNot",
+	">":  "isGreater",
+	">=": "isGreaterEqual",
+	"<":  "isLess",
+	"<=": "isLessEqual",
+}
+
+// wordOperators 单词运算符与多维表格运算符的对应关系，匹配时忽略大小写
+var wordOperators = map[string]string{
+	"is":             "is",
+	"isnot":          "isNot",
+	"contains":       "contains",
+	"doesnotcontain": "doesNotContain",
+	"isempty":        "isEmpty",
+	"isnotempty":     "isNotEmpty",
+	"isgreater":      "isGreater",
+	"isgreaterequal": "isGreaterEqual",
+	"isless":         "isLess",
+	"islessequal":    "isLessEqual",
+	"like":           "like",
+	"in":             "in",
+}
+
+// unaryOperators 不需要值的运算符
+var unaryOperators = map[string]bool{
+	"isEmpty":    true,
+	"isNotEmpty": true,
+}
+
+// queryParser 查询条件的递归下降解析器
+//
+//	expr      := and (OR and)*
+//	and       := unary (AND unary)*
+//	unary     := NOT unary | primary
+//	primary   := '(' expr ')' | predicate
+//	predicate := field operator [value]
+//	           | field [NOT] IN (value (',' value)* | value)
+//	           | field [NOT] LIKE value
+type queryParser struct {
+	query  string
+	tokens []token
+	pos    int
+
+	fieldType func(name string) FieldType // 查询字段类型，为 nil 时不区分字段类型
+
+	args     []interface{}          // 位置参数
+	argIndex int                    // 下一个位置参数的下标
+	named    map[string]interface{} // 命名参数
+	hasNamed bool                   // 查询条件中是否使用了命名参数
+}
+
+// parseQuery 解析查询条件并绑定参数，返回对应的过滤器
+func parseQuery(query string, args ...interface{}) (larkbitable.FilterInfo, error) {
+	return parseQueryFor(query, nil, args...)
+}
+
+// parseQueryFor 解析查询条件，fieldType 用于按字段类型展开 in 条件
+func parseQueryFor(query string, fieldType func(name string) FieldType, args ...interface{}) (larkbitable.FilterInfo, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+
+	p := &queryParser{query: query, tokens: tokens, fieldType: fieldType, named: make(map[string]interface{})}
+	for _, t := range tokens {
+		if t.kind == tokenNamed {
+			p.hasNamed = true
+		}
+	}
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case NamedArg:
+			p.named[val.Name] = val.Value
+		case *NamedArg:
+			p.named[val.Name] = val.Value
+		case map[string]interface{}:
+			// 使用了命名参数时，map 被视为命名参数集合
+			if p.hasNamed {
+				for k, v := range val {
+					p.named[k] = v
+				}
+				continue
+			}
+			p.args = append(p.args, arg)
+		default:
+			p.args = append(p.args, arg)
+		}
+	}
+
+	filter, err := p.parseExpr()
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return larkbitable.FilterInfo{}, p.errorAt(t, fmt.Sprintf("无法识别的内容 %q", t.text), nil)
+	}
+	if p.argIndex != len(p.args) {
+		return larkbitable.FilterInfo{}, p.errorAt(p.peek(), fmt.Sprintf("占位符 ? 数量为 %d，参数数量为 %d", p.argIndex, len(p.args)), ErrInvalidWhereParamsLength)
+	}
+	return filter, nil
+}
+
+func (p *queryParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeyword 当前 token 为指定关键字时前进并返回 true
+func (p *queryParser) acceptKeyword(keyword string) bool {
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.text, keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) errorAt(t token, msg string, err error) *ParseError {
+	return &ParseError{Query: p.query, Column: t.pos + 1, Msg: msg, Err: err}
+}
+
+func (p *queryParser) parseExpr() (larkbitable.FilterInfo, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return left, err
+	}
+	for {
+		t := p.peek()
+		if !p.acceptKeyword("or") {
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return right, err
+		}
+		if left, err = combineFilter(conjunctionOr, left, right); err != nil {
+			return left, p.errorAt(t, err.Error(), err)
+		}
+	}
+}
+
+func (p *queryParser) parseAnd() (larkbitable.FilterInfo, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return left, err
+	}
+	for {
+		t := p.peek()
+		if !p.acceptKeyword("and") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return right, err
+		}
+		if left, err = combineFilter(conjunctionAnd, left, right); err != nil {
+			return left, p.errorAt(t, err.Error(), err)
+		}
+	}
+}
+
+func (p *queryParser) parseUnary() (larkbitable.FilterInfo, error) {
+	t := p.peek()
+	if p.acceptKeyword("not") {
+		filter, err := p.parseUnary()
+		if err != nil {
+			return filter, err
+		}
+		negated, err := negateFilter(filter)
+		if err != nil {
+			return negated, p.errorAt(t, err.Error(), err)
+		}
+		return negated, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *queryParser) parsePrimary() (larkbitable.FilterInfo, error) {
+	t := p.peek()
+	if t.kind == tokenLParen {
+		p.next()
+		filter, err := p.parseExpr()
+		if err != nil {
+			return filter, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return filter, p.errorAt(closing, "缺少右括号 )", nil)
+		}
+		return filter, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *queryParser) parsePredicate() (larkbitable.FilterInfo, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != tokenWord && fieldToken.kind != tokenIdent {
+		return larkbitable.FilterInfo{}, p.errorAt(fieldToken, "缺少字段名", nil)
+	}
+	field := fieldToken.text
+
+	opToken := p.peek()
+	op, negate, err := p.parseOperator()
+	if err != nil {
+		return larkbitable.FilterInfo{}, err
+	}
+
+	if unaryOperators[op] {
+		return newConditionFilter(&larkbitable.Condition{FieldName: &field, Operator: &op, Value: []string{}}), nil
+	}
+
+	valueToken := p.peek()
+	var filter larkbitable.FilterInfo
+	switch op {
+	case "in":
+		values, err := p.parseInValues()
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = p.inFilter(field, values); err != nil {
+			return larkbitable.FilterInfo{}, p.errorAt(valueToken, err.Error(), err)
+		}
+	case "like":
+		value, err := p.parseValue()
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = likeFilter(field, value); err != nil {
+			return larkbitable.FilterInfo{}, p.errorAt(valueToken, err.Error(), err)
+		}
+	default:
+		value, err := p.parseValue()
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		if filter, err = buildCondition(field, op, value); err != nil {
+			return larkbitable.FilterInfo{}, p.errorAt(valueToken, err.Error(), err)
+		}
+	}
+
+	if negate {
+		negated, err := negateFilter(filter)
+		if err != nil {
+			return larkbitable.FilterInfo{}, p.errorAt(opToken, err.Error(), err)
+		}
+		filter = negated
+	}
+	return filter, nil
+}
+
+// parseInValues 解析 in 条件的值，支持 (a, b) 列表以及切片参数
+func (p *queryParser) parseInValues() ([]interface{}, error) {
+	if p.peek().kind != tokenLParen {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return expandInValues(nil, value), nil
+	}
+
+	p.next()
+	var values []interface{}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = expandInValues(values, value)
+		t := p.next()
+		if t.kind == tokenRParen {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, p.errorAt(t, "in 的值列表缺少逗号或右括号", nil)
+		}
+	}
+}
+
+// inFilter 生成 in 条件：多选字段使用一个 contains 条件，其它字段展开为 is 条件的 OR 条件组
+func (p *queryParser) inFilter(field string, values []interface{}) (larkbitable.FilterInfo, error) {
+	if p.fieldType == nil || p.fieldType(field) != FieldTypeMultiSelect {
+		return inFilter(field, values)
+	}
+	if len(values) == 0 {
+		return larkbitable.FilterInfo{}, fmt.Errorf("%w: %s", ErrEmptyInValues, field)
+	}
+	op := "contains"
+	cond := &larkbitable.Condition{FieldName: &field, Operator: &op, Value: []string{}}
+	for _, value := range values {
+		encoded, err := encodeConditionValue(op, value)
+		if err != nil {
+			return larkbitable.FilterInfo{}, err
+		}
+		cond.Value = append(cond.Value, encoded...)
+	}
+	return newConditionFilter(cond), nil
+}
+
+// expandInValues 将值追加到 values，切片参数展开为多个值
+func expandInValues(values []interface{}, value interface{}) []interface{} {
+	if _, isBytes := value.([]byte); !isBytes {
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			for i := 0; i < rv.Len(); i++ {
+				values = append(values, rv.Index(i).Interface())
+			}
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// likeFilter 将 like 条件转换为多维表格的条件：'%x%' 为 contains，不带 % 时为 is
+func likeFilter(field string, value interface{}) (larkbitable.FilterInfo, error) {
+	pattern, ok := value.(string)
+	if !ok {
+		return larkbitable.FilterInfo{}, fmt.Errorf("like 的值必须是字符串，实际为 %T", value)
+	}
+
+	op := "is"
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "%") && strings.HasSuffix(pattern, "%") {
+		op, pattern = "contains", pattern[1:len(pattern)-1]
+	}
+	if pattern == "" || strings.Contains(pattern, "%") {
+		return larkbitable.FilterInfo{}, fmt.Errorf("like 只支持 '%%关键字%%' 形式的模式：%q", value)
+	}
+	return newConditionFilter(&larkbitable.Condition{FieldName: &field, Operator: &op, Value: []string{pattern}}), nil
+}
+
+// parseOperator 解析运算符，支持符号、单词以及 is null、is not empty、not in、not like 等组合写法
+// negate 为 true 时表示条件需要取反
+func (p *queryParser) parseOperator() (op string, negate bool, err error) {
+	t := p.next()
+	switch t.kind {
+	case tokenSymbol:
+		return symbolOperators[t.text], false, nil
+	case tokenWord:
+		lower := strings.ToLower(t.text)
+		if lower == "is" {
+			negate := p.acceptKeyword("not")
+			if p.acceptKeyword("null") || p.acceptKeyword("empty") {
+				if negate {
+					return "isNotEmpty", false, nil
+				}
+				return "isEmpty", false, nil
+			}
+			if negate {
+				return "isNot", false, nil
+			}
+			return "is", false, nil
+		}
+		if lower == "not" {
+			if p.acceptKeyword("in") {
+				return "in", true, nil
+			}
+			if p.acceptKeyword("like") {
+				return "like", true, nil
+			}
+			return "", false, p.errorAt(t, "not 之后只能是 in 或 like", nil)
+		}
+		if op, ok := wordOperators[lower]; ok {
+			return op, false, nil
+		}
+		return "", false, p.errorAt(t, fmt.Sprintf("无法解析的 where condition operation: %s", t.text), nil)
+	case tokenEOF:
+		return "", false, p.errorAt(t, "缺少运算符", nil)
+	default:
+		return "", false, p.errorAt(t, fmt.Sprintf("无法解析的 where condition operation: %s", t.text), nil)
+	}
+}
+
+// parseValue 解析并绑定条件的值
+func (p *queryParser) parseValue() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenPlaceholder:
+		if p.argIndex >= len(p.args) {
+			return nil, p.errorAt(t, "占位符 ? 缺少对应的参数", ErrInvalidWhereParamsLength)
+		}
+		value := p.args[p.argIndex]
+		p.argIndex++
+		return value, nil
+	case tokenNamed:
+		value, ok := p.named[t.text]
+		if !ok {
+			return nil, p.errorAt(t, fmt.Sprintf("缺少命名参数 @%s", t.text), ErrInvalidWhereParamsLength)
+		}
+		return value, nil
+	case tokenString, tokenWord:
+		return t.text, nil
+	case tokenEOF:
+		return nil, p.errorAt(t, "缺少条件的值", nil)
+	default:
+		return nil, p.errorAt(t, fmt.Sprintf("无法识别的值 %q", t.text), nil)
+	}
+}
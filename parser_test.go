@@ -0,0 +1,156 @@
+package biorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+func TestParseQuery(t *testing.T) {
+	db := NewDB(nil)
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "operators without spaces",
+			tx:   db.Where("年龄>=? AND 年龄<=? AND 分数<>?", 18, 60, 0),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"年龄","operator":"isGreaterEqual","value":["18"]},
+				{"field_name":"年龄","operator":"isLessEqual","value":["60"]},
+				{"field_name":"分数","operator":"isNot","value":["0"]}]}`,
+		},
+		{
+			name: "nested expression",
+			tx:   db.Where("状态 = ? AND (金额 >= ? OR 负责人 contains ?)", "进行中", 100, "张三"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"金额","operator":"isGreaterEqual","value":["100"]},
+					{"field_name":"负责人","operator":"contains","value":["张三"]}]}]}`,
+		},
+		{
+			name: "named arguments",
+			tx: db.Where("金额 >= @amount and 状态 = @status",
+				Named("amount", 100), map[string]interface{}{"status": "进行中"}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"金额","operator":"isGreaterEqual","value":["100"]},
+				{"field_name":"状态","operator":"is","value":["进行中"]}]}`,
+		},
+		{
+			name: "literals and keywords",
+			tx:   db.Where("`任务 名称` = '写''文档' AND 备注 is not null AND NOT 标签 is empty"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"任务 名称","operator":"is","value":["写'文档"]},
+				{"field_name":"备注","operator":"isNotEmpty","value":[]},
+				{"field_name":"标签","operator":"isNotEmpty","value":[]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+}
+
+func TestParseInAndLike(t *testing.T) {
+	db := NewDB(nil)
+
+	tests := []struct {
+		name string
+		tx   *DB
+		want string
+	}{
+		{
+			name: "in slice",
+			tx:   db.Where("状态 = ?", "进行中").Where("优先级 in ?", []string{"P0", "P1"}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"状态","operator":"is","value":["进行中"]}],
+				"children":[{"conjunction":"or","conditions":[
+					{"field_name":"优先级","operator":"is","value":["P0"]},
+					{"field_name":"优先级","operator":"is","value":["P1"]}]}]}`,
+		},
+		{
+			name: "in list",
+			tx:   db.Where("优先级 IN ('P0', ?, @p)", "P1", Named("p", []int{2, 3})),
+			want: `{"conjunction":"or","conditions":[
+				{"field_name":"优先级","operator":"is","value":["P0"]},
+				{"field_name":"优先级","operator":"is","value":["P1"]},
+				{"field_name":"优先级","operator":"is","value":["2"]},
+				{"field_name":"优先级","operator":"is","value":["3"]}]}`,
+		},
+		{
+			name: "not in",
+			tx:   db.Where("优先级 not in ?", []string{"P0", "P1"}),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"优先级","operator":"isNot","value":["P0"]},
+				{"field_name":"优先级","operator":"isNot","value":["P1"]}]}`,
+		},
+		{
+			name: "like",
+			tx:   db.Where("名称 like ? AND 备注 NOT LIKE '%草稿%' AND 编号 like 'A1'", "%文档%"),
+			want: `{"conjunction":"and","conditions":[
+				{"field_name":"名称","operator":"contains","value":["文档"]},
+				{"field_name":"备注","operator":"doesNotContain","value":["草稿"]},
+				{"field_name":"编号","operator":"is","value":["A1"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFilterBody(t, tt.tx, tt.want)
+		})
+	}
+
+	// 开启 ValidateFields 时，多选字段的 in 使用 contains
+	srv := newFieldServer()
+	defer srv.Close()
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldTags", Name: "标签", Type: 4, UiType: "MultiSelect"})
+	typed := newServerDB(srv)
+	typed.Config.ValidateFields = true
+	table := typed.Base(testAppToken).Table(testTableId)
+	assertFilterBody(t, table.Where("标签 in ?", []string{"前端", "后端"}), `{"conjunction":"and","conditions":[
+		{"field_name":"标签","operator":"contains","value":["前端","后端"]}]}`)
+	assertFilterBody(t, table.Where("标签 not in ('前端')"), `{"conjunction":"and","conditions":[
+		{"field_name":"标签","operator":"doesNotContain","value":["前端"]}]}`)
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		args   []interface{}
+		column int
+		target error
+	}{
+		{name: "missing argument", query: "a = ? AND b = ?", args: []interface{}{1}, column: 15, target: ErrInvalidWhereParamsLength},
+		{name: "extra argument", query: "a = ?", args: []interface{}{1, 2}, column: 6, target: ErrInvalidWhereParamsLength},
+		{name: "missing named", query: "金额 >= @amount", column: 7, target: ErrInvalidWhereParamsLength},
+		{name: "unknown operator", query: "金额 between ?", args: []interface{}{1}, column: 4},
+		{name: "missing paren", query: "(a = ? OR b = ?", args: []interface{}{1, 2}, column: 16},
+		{name: "unclosed quote", query: "a = 'x", column: 5},
+		{name: "empty in", query: "a in ?", args: []interface{}{[]string{}}, column: 6, target: ErrEmptyInValues},
+		{name: "unclosed in list", query: "a in (1, 2", column: 11},
+		{name: "prefix like", query: "a like 'x%'", column: 8},
+		{name: "not without in", query: "a not = 1", column: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseQuery(tt.query, tt.args...)
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected *ParseError, got %v", err)
+			}
+			if parseErr.Column != tt.column {
+				t.Errorf("column = %d, want %d (%v)", parseErr.Column, tt.column, err)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("expected errors.Is(err, %v), got %v", tt.target, err)
+			}
+		})
+	}
+}
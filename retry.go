@@ -0,0 +1,295 @@
+package biorm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+const (
+	// CodeRateLimited 飞书开放平台的频率限制错误码
+	CodeRateLimited = 99991400
+	// CodeTooManyRequest 多维表格接口的请求过于频繁错误码
+	CodeTooManyRequest = 1254290
+	// CodeWriteConflict 多维表格并发写入冲突错误码
+	CodeWriteConflict = 1254291
+	// CodeRequestTimeout 多维表格请求超时错误码
+	CodeRequestTimeout = 1255040
+
+	// rateLimitResetHeader 频率限制重置的剩余秒数
+	rateLimitResetHeader = "x-ogw-ratelimit-reset"
+)
+
+// RetryPolicy 请求失败后的重试策略
+//
+// 频率限制（99991400、1254290、HTTP 429）与写入冲突（1254291）时请求未被处理，总是会重试；
+// 5xx、请求超时（1255040）以及网络错误时请求可能已被处理，只有幂等的请求才会重试。
+// 查询、更新、删除是幂等的，新增记录在设置了 ClientToken 时是幂等的。
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，为 0 时不重试
+	BaseDelay  time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay   time.Duration // 单次等待的最大时间
+
+	// 新增记录未设置 ClientToken 时自动生成，使新增记录可以安全地重试
+	AutoClientToken bool
+}
+
+// DefaultRetryPolicy 默认重试策略：最多重试 3 次，等待 500ms、1s、2s，并加入随机抖动
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxRetries:      3,
+		BaseDelay:       500 * time.Millisecond,
+		MaxDelay:        10 * time.Second,
+		AutoClientToken: true,
+	}
+}
+
+// backoff 返回第 attempt 次重试（从 0 开始）前的等待时间，在 [d/2, d] 之间随机抖动
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	if d <= 0 {
+		d = 500 * time.Millisecond
+	}
+	d = time.Duration(float64(d) * math.Pow(2, float64(attempt)))
+	if p.MaxDelay > 0 && (d > p.MaxDelay || d <= 0) {
+		d = p.MaxDelay
+	}
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + mrand.Int63n(half+1))
+}
+
+// rateLimiter 令牌桶限流，同一个 NewDB 创建的实例共享
+type rateLimiter struct {
+	mu           sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time // 服务端返回频率限制后，所有请求等待到该时间
+}
+
+// reserve 取出一个令牌，返回需要等待的时间；rate 为 0 时不限流
+func (l *rateLimiter) reserve(rate float64, burst int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	if rate > 0 {
+		if burst <= 0 {
+			burst = int(math.Ceil(rate))
+		}
+		if l.last.IsZero() {
+			l.tokens = float64(burst)
+		} else {
+			l.tokens = math.Min(float64(burst), l.tokens+now.Sub(l.last).Seconds()*rate)
+		}
+		l.last = now
+		l.tokens--
+		if l.tokens < 0 {
+			wait = time.Duration(-l.tokens / rate * float64(time.Second))
+		}
+	}
+	if blocked := l.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+	return wait
+}
+
+// cancel 归还一个未使用的令牌
+func (l *rateLimiter) cancel(rate float64, burst int) {
+	if rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	l.tokens = math.Min(float64(burst), l.tokens+1)
+}
+
+// block 在 d 时长内暂停所有请求
+func (l *rateLimiter) block(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+// wait 等待直到可以发起请求，context 被取消时返回其错误
+func (l *rateLimiter) wait(ctx context.Context, rate float64, burst int) error {
+	d := l.reserve(rate, burst)
+	if err := sleepContext(ctx, d); err != nil {
+		l.cancel(rate, burst)
+		return err
+	}
+	return nil
+}
+
+// apiCall 描述一次 API 请求，用于限流、重试与日志
+type apiCall struct {
+	Method     string
+	Path       string
+	Body       interface{}
+	Idempotent bool // 请求可能已被处理时是否可以重试
+}
+
+// execute 发起一次 API 请求，按 Config 中的限流与重试策略执行
+// fn 需要将 SDK 返回的响应保存到调用方的变量中，并返回响应与错误
+func (db *DB) execute(call apiCall, fn func(ctx context.Context) (interface{}, error)) error {
+	ctx := db.Statement.Context
+	policy := db.Config.Retry
+	if _, err := db.resolveApi(); err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if db.limiter != nil {
+			if err := db.limiter.wait(ctx, db.Config.RateLimit, db.Config.RateBurst); err != nil {
+				return err
+			}
+		}
+
+		begin := time.Now()
+		resp, err := fn(ctx)
+		db.trace(begin, call.Method, call.Path, call.Body, resp, err)
+
+		retryable, reset := classifyResponse(resp, err, call.Idempotent)
+		if reset > 0 && db.limiter != nil {
+			db.limiter.block(reset)
+		}
+		if !retryable || policy == nil || attempt >= policy.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if reset > delay {
+			delay = reset
+		}
+		db.getLogger().Warn(ctx, "[retry] %s %s 第 %d 次重试，等待 %v：%v",
+			call.Method, call.Path, attempt+1, delay, describeFailure(resp, err))
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// classifyResponse 判断请求是否可以重试，并返回服务端要求等待的时间
+func classifyResponse(resp interface{}, err error, idempotent bool) (retryable bool, reset time.Duration) {
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return false, 0
+	}
+
+	apiResp := apiRespOf(resp)
+	status := 0
+	if apiResp != nil {
+		status = apiResp.StatusCode
+		reset = rateLimitReset(apiResp.Header)
+	}
+
+	switch code := codeOf(resp); {
+	case code == CodeRateLimited || code == CodeTooManyRequest || status == http.StatusTooManyRequests:
+		return true, reset
+	case code == CodeWriteConflict:
+		return true, 0
+	case code == CodeRequestTimeout || status >= http.StatusInternalServerError:
+		return idempotent, 0
+	case err != nil && apiResp == nil:
+		// 网络错误，请求可能已经到达服务端
+		return idempotent, 0
+	}
+	return false, 0
+}
+
+// rateLimitReset 读取 x-ogw-ratelimit-reset 响应头
+func rateLimitReset(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+	seconds, err := strconv.ParseFloat(header.Get(rateLimitResetHeader), 64)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// codeOf 取出响应中的业务错误码
+func codeOf(resp interface{}) int {
+	if codeErr := codeErrorOf(resp); codeErr != nil {
+		return codeErr.Code
+	}
+	return 0
+}
+
+// codeErrorOf 从 SDK 返回的响应中取出 CodeError，*larkcore.ApiResp 会解析响应体
+func codeErrorOf(resp interface{}) *larkcore.CodeError {
+	if r, ok := resp.(*larkcore.ApiResp); ok {
+		if r == nil || len(r.RawBody) == 0 {
+			return nil
+		}
+		codeErr := &larkcore.CodeError{}
+		if err := json.Unmarshal(r.RawBody, codeErr); err != nil {
+			return nil
+		}
+		return codeErr
+	}
+	v := reflectStruct(resp)
+	if !v.IsValid() {
+		return nil
+	}
+	if f := v.FieldByName("CodeError"); f.IsValid() {
+		if codeErr, ok := f.Interface().(larkcore.CodeError); ok {
+			return &codeErr
+		}
+	}
+	return nil
+}
+
+// describeFailure 返回用于日志的失败原因
+func describeFailure(resp interface{}, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if codeErr := codeErrorOf(resp); codeErr != nil && codeErr.Code != 0 {
+		return fmt.Sprintf("code=%d msg=%s", codeErr.Code, codeErr.Msg)
+	}
+	if apiResp := apiRespOf(resp); apiResp != nil {
+		return fmt.Sprintf("status=%d", apiResp.StatusCode)
+	}
+	return "unknown"
+}
+
+// clientTokenFor 返回新增记录使用的 ClientToken，未设置时按重试策略自动生成
+func (db *DB) clientTokenFor() string {
+	if db.Statement.ClientToken != "" {
+		return db.Statement.ClientToken
+	}
+	if p := db.Config.Retry; p != nil && p.MaxRetries > 0 && p.AutoClientToken {
+		return newClientToken()
+	}
+	return ""
+}
+
+// newClientToken 生成随机的 uuid v4
+func newClientToken() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
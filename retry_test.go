@@ -0,0 +1,165 @@
+package biorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2015WUJI01/biorm/logger"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := &rateLimiter{}
+	for i := 0; i < 2; i++ {
+		if d := l.reserve(10, 2); d != 0 {
+			t.Fatalf("reserve #%d waits %v, want 0 within burst", i, d)
+		}
+	}
+	if d := l.reserve(10, 2); d <= 0 || d > 100*time.Millisecond {
+		t.Errorf("reserve after burst waits %v, want (0, 100ms]", d)
+	}
+	if d := l.reserve(0, 0); d != 0 {
+		t.Errorf("reserve without rate limit waits %v, want 0", d)
+	}
+
+	l.block(time.Second)
+	if d := l.reserve(0, 0); d < 900*time.Millisecond {
+		t.Errorf("reserve while blocked waits %v, want about 1s", d)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	for attempt, limit := range []time.Duration{100, 200, 300, 300} {
+		limit *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			if d := p.backoff(attempt); d < limit/2 || d > limit {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, limit/2, limit)
+			}
+		}
+	}
+}
+
+func TestClassifyResponse(t *testing.T) {
+	rateLimited := &larkcore.ApiResp{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"X-Ogw-Ratelimit-Reset": []string{"2"}},
+		RawBody:    []byte(`{"code":99991400,"msg":"request trigger frequency limit"}`),
+	}
+	serverError := &larkcore.ApiResp{StatusCode: http.StatusBadGateway}
+	invalid := &larkcore.ApiResp{StatusCode: http.StatusOK, RawBody: []byte(`{"code":1254045,"msg":"FieldNameNotFound"}`)}
+
+	tests := []struct {
+		name       string
+		resp       interface{}
+		err        error
+		idempotent bool
+		retryable  bool
+		reset      time.Duration
+	}{
+		{name: "rate limited", resp: rateLimited, retryable: true, reset: 2 * time.Second},
+		{name: "server error idempotent", resp: serverError, idempotent: true, retryable: true},
+		{name: "server error not idempotent", resp: serverError},
+		{name: "network error", err: errors.New("connection reset"), idempotent: true, retryable: true},
+		{name: "context canceled", err: context.Canceled, idempotent: true},
+		{name: "invalid request", resp: invalid, idempotent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retryable, reset := classifyResponse(tt.resp, tt.err, tt.idempotent)
+			if retryable != tt.retryable || reset != tt.reset {
+				t.Errorf("classifyResponse() = (%v, %v), want (%v, %v)", retryable, reset, tt.retryable, tt.reset)
+			}
+		})
+	}
+}
+
+func TestExecuteRetries(t *testing.T) {
+	db := NewDB(nil)
+	db.Config.Logger = logger.Discard
+	db.Config.Retry = &RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	calls := 0
+	err := db.execute(apiCall{Method: http.MethodPost, Path: "/test"}, func(ctx context.Context) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return &larkcore.ApiResp{StatusCode: http.StatusTooManyRequests}, nil
+		}
+		return &larkcore.ApiResp{StatusCode: http.StatusOK, RawBody: []byte(`{"code":0}`)}, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("execute() = %v after %d calls, want success after 3 calls", err, calls)
+	}
+
+	calls = 0
+	_ = db.execute(apiCall{Method: http.MethodPost, Path: "/test"}, func(ctx context.Context) (interface{}, error) {
+		calls++
+		return &larkcore.ApiResp{StatusCode: http.StatusInternalServerError}, nil
+	})
+	if calls != 1 {
+		t.Errorf("non-idempotent call retried %d times on 5xx, want no retry", calls-1)
+	}
+}
+
+func TestClientTokenFor(t *testing.T) {
+	db := NewDB(nil)
+	if token := db.clientTokenFor(); len(token) != 36 || token[14] != '4' {
+		t.Errorf("clientTokenFor() = %q, want generated uuid v4", token)
+	}
+	if token := db.Idempotent("fe599b60-450f-46ff-b2ef-9f6675625b97").clientTokenFor(); token != "fe599b60-450f-46ff-b2ef-9f6675625b97" {
+		t.Errorf("clientTokenFor() = %q, want the configured token", token)
+	}
+	db = NewDB(nil)
+	db.Config.Retry = nil
+	if token := db.clientTokenFor(); token != "" {
+		t.Errorf("clientTokenFor() without retry = %q, want empty", token)
+	}
+}
+
+// lineWriter 记录每一行日志
+type lineWriter struct {
+	lines []string
+}
+
+func (w *lineWriter) Printf(format string, args ...interface{}) {
+	w.lines = append(w.lines, fmt.Sprintf(format, args...))
+}
+
+func TestDebugLogsRequests(t *testing.T) {
+	db, srv, _ := newSearchTestDB(3)
+	defer srv.Close()
+	w := &lineWriter{}
+	db.Config.Logger = logger.New(w, logger.Config{LogLevel: logger.Warn, TraceBody: true})
+
+	if _, tx := db.Where("序号 = ?", 1).Records(); tx.Error != nil || len(w.lines) != 0 {
+		t.Fatalf("Warn level logged %q, err %v", w.lines, tx.Error)
+	}
+
+	if _, tx := db.Debug().Where("序号 = ?", 1).Records(); tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	var request string
+	for _, line := range w.lines {
+		if strings.HasPrefix(line, "[info]") && strings.Contains(line, "/records/search") {
+			request = line
+		}
+	}
+	for _, want := range []string{
+		"POST /open-apis/bitable/v1/apps/" + testAppToken + "/tables/" + testTableId + "/records/search status=200",
+		"\nrequest: {",
+		`"filter":{"conditions":[{"field_name":"序号","operator":"is","value":["1"]}],"conjunction":"and"}`,
+		"\nresponse: {\"code\":0",
+	} {
+		if !strings.Contains(request, want) {
+			t.Errorf("request log %q does not contain %q", request, want)
+		}
+	}
+	if !strings.HasPrefix(w.lines[0], "[debug]") {
+		t.Errorf("first line = %q, want debug output while building conditions", w.lines[0])
+	}
+}
@@ -0,0 +1,438 @@
+package biorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// MaxPageSize 查询记录接口单页的最大记录数
+const MaxPageSize = 500
+
+// ErrStopIteration 在 Each、FindInBatches 的回调中返回，用于提前结束遍历且不视为错误
+var ErrStopIteration = errors.New("stop iteration")
+
+// Rows 查询结果的迭代器，按页请求记录，内存中只保留当前页
+// Usage:
+//
+//	rows, tx := db.Base(appToken).Table(tableId).Where("状态 = ?", "进行中").Rows()
+//	defer rows.Close()
+//	for rows.Next() {
+//		record := rows.Record()
+//	}
+//	if err := rows.Err(); err != nil { ... }
+type Rows struct {
+	tx   *DB
+	body map[string]interface{}
+
+	pageSize  int
+	pageToken string // 下一页的 page_token
+	hasMore   bool
+	fetched   bool // 是否已请求过至少一页
+	limit     int  // 最多返回的记录数，为 0 时不限制
+	offset    int  // 尚未跳过的记录数
+	seen      int  // 已请求到的记录数
+
+	records []*larkbitable.AppTableRecord // 当前页
+	index   int                           // 当前记录在 records 中的下标
+	total   int
+
+	closed bool
+	err    error
+}
+
+// Rows 返回查询结果的迭代器
+func (db *DB) Rows() (rows *Rows, tx *DB) {
+	tx = db.Clone()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	// 打印调试信息
+	tx.debugf("[Rows] AppToken: %s, TableId: %s", tx.AppToken, tx.TableId)
+	if tx.Statement.Filter.Conjunction != nil {
+		tx.debugf("[Rows] Conjunction: %s", *tx.Statement.Filter.Conjunction)
+	} else {
+		tx.debugf("[Rows] Conjunction: nil")
+	}
+	for i, cond := range tx.Statement.Filter.Conditions {
+		if cond != nil && cond.FieldName != nil && cond.Operator != nil {
+			tx.debugf("[Rows] 条件[%d]: 字段=%s, 操作符=%s, 值=%v",
+				i, *cond.FieldName, *cond.Operator, cond.Value)
+		}
+	}
+	tx.debugf("[Rows] 条件组数量: %d", len(tx.Statement.Filter.Children))
+
+	if tx.Config.ValidateFields {
+		if err := tx.validateFieldNames(); err != nil {
+			tx.Error = err
+			return
+		}
+		if err := tx.encodeFilterFields(); err != nil {
+			tx.Error = err
+			return
+		}
+	}
+
+	pageSize := tx.Statement.PageSize
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	rows = &Rows{
+		tx:        tx,
+		body:      tx.Statement.buildSearchBody(),
+		pageSize:  pageSize,
+		pageToken: tx.Statement.PageToken,
+		hasMore:   true,
+		limit:     tx.Statement.Limit,
+		offset:    tx.Statement.Offset,
+		index:     -1,
+	}
+	return
+}
+
+// Next 移动到下一条记录，当前页读完时请求下一页；没有更多记录或出错时返回 false
+func (r *Rows) Next() bool {
+	if r == nil || r.closed || r.err != nil {
+		return false
+	}
+	r.index++
+	for r.index >= len(r.records) {
+		if !r.nextPage() {
+			return false
+		}
+	}
+	return true
+}
+
+// Record 返回当前记录
+func (r *Rows) Record() *larkbitable.AppTableRecord {
+	if r == nil || r.index < 0 || r.index >= len(r.records) {
+		return nil
+	}
+	return r.records[r.index]
+}
+
+// Scan 将当前记录解码到 dest，dest 为结构体指针或 map 指针
+func (r *Rows) Scan(dest interface{}) error {
+	record := r.Record()
+	if record == nil {
+		return ErrRecordNotFound
+	}
+	return decodeRecords([]*larkbitable.AppTableRecord{record}, dest)
+}
+
+// Err 返回遍历过程中的错误
+func (r *Rows) Err() error {
+	if r == nil {
+		return nil
+	}
+	return r.err
+}
+
+// PageToken 返回下一页的 page_token，没有更多记录时为空
+// 通过 db.PageToken(token) 可以从该页继续遍历，当前页中未读取的记录不会被再次返回
+func (r *Rows) PageToken() string {
+	if r == nil || !r.hasMore {
+		return ""
+	}
+	return r.pageToken
+}
+
+// Total 返回查询结果的总记录数，在请求第一页后有效
+func (r *Rows) Total() int {
+	if r == nil {
+		return 0
+	}
+	return r.total
+}
+
+// Close 结束遍历，释放当前页的记录；出错时保留 ApiResp 与 CodeError 以便排查
+func (r *Rows) Close() error {
+	if r == nil || r.closed {
+		return nil
+	}
+	r.closed = true
+	r.records = nil
+	if !r.tx.hasError() {
+		r.tx.Finalize()
+	}
+	return nil
+}
+
+// nextPage 请求下一页记录，没有更多记录或出错时返回 false
+func (r *Rows) nextPage() bool {
+	if !r.hasMore || (r.limit > 0 && r.seen >= r.limit) {
+		return false
+	}
+
+	// 跳过 Offset 指定的记录，每次请求恰好跳到偏移位置，之后的页从偏移位置开始
+	for r.offset > 0 {
+		size := r.offset
+		if size > MaxPageSize {
+			size = MaxPageSize
+		}
+		data, ok := r.fetch(size)
+		if !ok {
+			return false
+		}
+		r.offset -= len(data.Items)
+		if !r.hasMore || len(data.Items) == 0 {
+			r.offset = 0
+			r.records = nil
+			return r.hasMore
+		}
+	}
+
+	pageSize := r.pageSize
+	if r.limit > 0 && r.limit-r.seen < pageSize {
+		pageSize = r.limit - r.seen
+	}
+	data, ok := r.fetch(pageSize)
+	if !ok {
+		return false
+	}
+
+	r.records = data.Items
+	if r.limit > 0 && len(r.records) > r.limit-r.seen {
+		r.records = r.records[:r.limit-r.seen]
+	}
+	r.seen += len(r.records)
+	r.index = 0
+	return len(r.records) > 0 || (r.hasMore && (r.limit <= 0 || r.seen < r.limit))
+}
+
+// fetch 请求 pageSize 条记录并更新分页状态，出错时返回 false
+func (r *Rows) fetch(pageSize int) (*larkbitable.SearchAppTableRecordRespData, bool) {
+	tx := r.tx
+	if r.fetched {
+		if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+			r.setError(err)
+			return nil, false
+		}
+	}
+
+	data, err := tx.searchPage(r.body, r.pageToken, pageSize)
+	r.fetched = true
+	if err != nil {
+		r.setError(err)
+		return nil, false
+	}
+
+	r.hasMore = data.HasMore != nil && *data.HasMore
+	r.pageToken = ""
+	if data.PageToken != nil {
+		r.pageToken = *data.PageToken
+	}
+	if data.Total != nil {
+		r.total = *data.Total
+	}
+	if r.hasMore && r.pageToken == "" {
+		r.hasMore = false
+	}
+	return data, true
+}
+
+func (r *Rows) setError(err error) {
+	r.err = err
+	r.tx.Error = err
+}
+
+// searchPage 请求一页查询结果，失败时记录 ApiResp 与 CodeError
+func (db *DB) searchPage(body map[string]interface{}, pageToken string, pageSize int) (*larkbitable.SearchAppTableRecordRespData, error) {
+	apiReq := larkcore.ApiReq{
+		HttpMethod: http.MethodPost,
+		ApiPath:    "/open-apis/bitable/v1/apps/:app_token/tables/:table_id/records/search",
+		Body:       body,
+		QueryParams: larkcore.QueryParams{
+			"user_id_type": []string{db.Statement.UserIdType},
+			"page_token":   []string{pageToken},
+			"page_size":    []string{strconv.Itoa(pageSize)},
+		},
+		PathParams: larkcore.PathParams{
+			"app_token": db.AppToken,
+			"table_id":  db.TableId,
+		},
+		SupportedAccessTokenTypes: []larkcore.AccessTokenType{larkcore.AccessTokenTypeTenant},
+	}
+
+	// 发起请求
+	var resp *larkcore.ApiResp
+	err := db.execute(apiCall{
+		Method:     http.MethodPost,
+		Path:       "/open-apis/bitable/v1/apps/" + db.AppToken + "/tables/" + db.TableId + "/records/search",
+		Body:       body,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = db.api().Do(ctx, &apiReq)
+		return resp, err
+	})
+
+	// 处理错误
+	if resp != nil {
+		db.ApiResp = resp
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || resp.RawBody == nil {
+		return nil, fmt.Errorf("response body is nil: %w", ErrResponseIsNil)
+	}
+
+	var response larkbitable.SearchAppTableRecordResp
+	if err := json.Unmarshal(resp.RawBody, &response); err != nil {
+		return nil, fmt.Errorf("json unmarshal response body failed: %w", err)
+	}
+	if !response.Success() {
+		db.CodeError = &response.CodeError
+		return nil, response.CodeError
+	}
+	if response.Data == nil {
+		return nil, fmt.Errorf("response data is nil: %w", ErrResponseIsNil)
+	}
+	return response.Data, nil
+}
+
+// Each 逐条遍历查询结果，每次只请求一页记录
+// fn 返回 ErrStopIteration 时停止遍历，返回其它错误时停止遍历并设置到 tx.Error
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).Each(func(record *larkbitable.AppTableRecord) error {
+//		return nil
+//	})
+func (db *DB) Each(fn func(record *larkbitable.AppTableRecord) error) (tx *DB) {
+	rows, tx := db.Rows()
+	if tx.hasError() {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows.Record()); err != nil {
+			if !errors.Is(err, ErrStopIteration) {
+				tx.Error = err
+			}
+			break
+		}
+	}
+	return
+}
+
+// FindInBatches 按页查询记录并解码到 dest，每页最多 batchSize 条，每页调用一次 fn
+// fn 中的 tx.Statement.PageToken 为下一页的 page_token，可用于之后通过 PageToken 继续遍历。
+// fn 返回 ErrStopIteration 时停止遍历，返回其它错误时停止遍历并设置到 tx.Error
+// Usage:
+//
+//	var tasks []Task
+//	tx := db.Base(appToken).Table(tableId).FindInBatches(&tasks, 200, func(tx *biorm.DB, batch int) error {
+//		return nil
+//	})
+func (db *DB) FindInBatches(dest interface{}, batchSize int, fn func(tx *DB, batch int) error) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	tx.Statement.PageSize = batchSize
+	tx.Statement.Dest = dest
+
+	rows, tx := tx.Rows()
+	if tx.hasError() {
+		return
+	}
+	defer rows.Close()
+
+	batch := 0
+	for rows.nextPage() {
+		if len(rows.records) == 0 {
+			continue
+		}
+		if err := decodeRecords(rows.records, dest); err != nil {
+			tx.Error = err
+			return
+		}
+		tx.RowsAffected += int64(len(rows.records))
+		tx.Statement.PageToken = rows.PageToken()
+
+		if err := fn(tx, batch); err != nil {
+			if !errors.Is(err, ErrStopIteration) {
+				tx.Error = err
+			}
+			return
+		}
+		batch++
+	}
+	return
+}
+
+// PageToken 从指定的 page_token 开始查询，用于继续之前中断的遍历
+func (db *DB) PageToken(pageToken string) (tx *DB) {
+	tx = db.getInstance()
+	tx.Statement.PageToken = pageToken
+	return tx
+}
+
+// Page 分页查询的结果
+type Page struct {
+	Items      []*larkbitable.AppTableRecord
+	NextCursor string // 下一页的游标，没有更多记录时为空
+	HasMore    bool
+	Total      int // 满足条件的记录总数
+}
+
+// Paginate 查询一页记录，cursor 为上一页返回的 NextCursor，第一页传空字符串
+// 与 Offset 一起使用时，先跳过 offset 条记录再返回一页，可用于按页码查询
+// Usage:
+//
+//	// 游标翻页
+//	page, tx := db.Base(appToken).Table(tableId).Order("创建时间", true).Paginate(20, cursor)
+//	// 查询第 3 页
+//	page, tx = db.Base(appToken).Table(tableId).Order("创建时间", true).Offset(40).Paginate(20, "")
+func (db *DB) Paginate(pageSize int, cursor string) (page *Page, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+	if pageSize <= 0 || pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	tx.Statement.PageSize = pageSize
+	tx.Statement.Limit = pageSize
+	tx.Statement.PageToken = cursor
+
+	rows, tx := tx.Rows()
+	if tx.hasError() {
+		return
+	}
+	defer rows.Close()
+
+	rows.nextPage()
+	if rows.Err() != nil {
+		return
+	}
+	page = &Page{
+		Items:      rows.records,
+		NextCursor: rows.PageToken(),
+		HasMore:    rows.hasMore,
+		Total:      rows.total,
+	}
+	if page.Items == nil {
+		page.Items = []*larkbitable.AppTableRecord{}
+	}
+	return
+}
@@ -0,0 +1,383 @@
+package biorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+	"github.com/2015WUJI01/biorm/logger"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+const (
+	testAppToken = "app"
+	testTableId  = "tbl0xe5g8PP3U3cS"
+)
+
+// newServerDB 返回连接到 biormtest 测试服务器的 DB
+func newServerDB(srv *biormtest.Server) *DB {
+	db := NewDB(srv.Client())
+	db.Config.RequestInterval = 0
+	db.Config.Logger = logger.Discard
+	return db
+}
+
+// newSearchTestDB 返回选中测试数据表的 DB，数据表中共 total 条记录，序号依次为 0 到 total-1，
+// 调用方需要关闭返回的 biormtest.Server
+func newSearchTestDB(total int) (*DB, *biormtest.Server, []string) {
+	srv := biormtest.NewServer()
+	srv.AddTable(testAppToken, testTableId, "任务")
+	fields := make([]map[string]interface{}, 0, total)
+	for i := 0; i < total; i++ {
+		fields = append(fields, map[string]interface{}{"序号": i})
+	}
+	ids := srv.Insert(testAppToken, testTableId, fields...)
+	return newServerDB(srv).Base(testAppToken).Table(testTableId), srv, ids
+}
+
+// searchRequests 返回查询记录请求的 page_size 与请求体
+func searchRequests(srv *biormtest.Server) (pageSizes []int, bodies []map[string]interface{}) {
+	for _, r := range srv.Requests() {
+		if !strings.HasSuffix(r.Path, "/records/search") {
+			continue
+		}
+		query, _ := url.ParseQuery(r.Query)
+		size, _ := strconv.Atoi(query.Get("page_size"))
+		pageSizes = append(pageSizes, size)
+		var body map[string]interface{}
+		_ = json.Unmarshal(r.Body, &body)
+		bodies = append(bodies, body)
+	}
+	return
+}
+
+func TestRowsWalksPages(t *testing.T) {
+	db, srv, ids := newSearchTestDB(1201)
+	defer srv.Close()
+
+	rows, tx := db.Rows()
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		if id := *rows.Record().RecordId; id != ids[count] {
+			t.Fatalf("record %d = %s", count, id)
+		}
+		count++
+	}
+	if rows.Err() != nil || count != 1201 || rows.Total() != 1201 {
+		t.Errorf("walked %d records (total %d), err %v", count, rows.Total(), rows.Err())
+	}
+	if pageSizes, _ := searchRequests(srv); len(pageSizes) != 3 {
+		t.Errorf("requested %d pages, want 3", len(pageSizes))
+	}
+}
+
+func TestEachStopsEarly(t *testing.T) {
+	db, srv, _ := newSearchTestDB(1201)
+	defer srv.Close()
+
+	count := 0
+	tx := db.Each(func(record *larkbitable.AppTableRecord) error {
+		count++
+		if count == 10 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if pageSizes, _ := searchRequests(srv); tx.Error != nil || count != 10 || len(pageSizes) != 1 {
+		t.Errorf("Each stopped after %d records and %d pages, err %v", count, len(pageSizes), tx.Error)
+	}
+
+	boom := errors.New("boom")
+	if tx := db.Each(func(*larkbitable.AppTableRecord) error { return boom }); tx.Error != boom {
+		t.Errorf("Each error = %v, want %v", tx.Error, boom)
+	}
+}
+
+func TestFindInBatchesResume(t *testing.T) {
+	type row struct {
+		Seq int `biorm:"序号"`
+	}
+	db, srv, _ := newSearchTestDB(250)
+	defer srv.Close()
+
+	var batch []row
+	var token string
+	tx := db.FindInBatches(&batch, 100, func(tx *DB, n int) error {
+		token = tx.Statement.PageToken
+		return ErrStopIteration
+	})
+	if tx.Error != nil || len(batch) != 100 || token != "100" {
+		t.Fatalf("first batch: %d rows, token %q, err %v", len(batch), token, tx.Error)
+	}
+
+	var seqs []int
+	tx = db.PageToken(token).FindInBatches(&batch, 100, func(tx *DB, n int) error {
+		for _, r := range batch {
+			seqs = append(seqs, r.Seq)
+		}
+		return nil
+	})
+	if tx.Error != nil || len(seqs) != 150 || seqs[0] != 100 || tx.RowsAffected != 150 {
+		t.Errorf("resumed scan: %d rows starting at %v, err %v", len(seqs), seqs, tx.Error)
+	}
+	if pageSizes, _ := searchRequests(srv); pageSizes[0] != 100 {
+		t.Errorf("page_size = %d, want 100", pageSizes[0])
+	}
+}
+
+func TestLimitAcrossPages(t *testing.T) {
+	db, srv, _ := newSearchTestDB(1201)
+	defer srv.Close()
+
+	records, tx := db.Limit(750).Records()
+	if tx.Error != nil || len(records) != 750 {
+		t.Fatalf("Limit(750) returned %d records, err %v", len(records), tx.Error)
+	}
+	if pageSizes, _ := searchRequests(srv); !reflect.DeepEqual(pageSizes, []int{500, 250}) {
+		t.Errorf("page sizes = %v, want [500 250]", pageSizes)
+	}
+}
+
+func TestFirstLastCount(t *testing.T) {
+	type row struct {
+		Id  string `biorm:"record_id"`
+		Seq int    `biorm:"序号"`
+	}
+	db, srv, ids := newSearchTestDB(1201)
+	defer srv.Close()
+
+	var first row
+	tx := db.First(&first)
+	if pageSizes, _ := searchRequests(srv); tx.Error != nil || first.Id != ids[0] || pageSizes[0] != 1 {
+		t.Errorf("First = %+v, page_size %v, err %v", first, pageSizes, tx.Error)
+	}
+
+	srv.ResetRequests()
+	var last row
+	if tx := db.Order("序号").Last(&last); tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	_, bodies := searchRequests(srv)
+	sort := bodies[0]["sort"].([]interface{})[0].(map[string]interface{})
+	if sort["desc"] != true {
+		t.Errorf("Last sort = %v, want desc", sort)
+	}
+
+	srv.ResetRequests()
+	if tx := db.Last(&last); !errors.Is(tx.Error, ErrOrderRequired) {
+		t.Errorf("Last without Order error = %v, want ErrOrderRequired", tx.Error)
+	}
+	if requests := srv.Requests(); len(requests) != 0 {
+		t.Errorf("Last without Order sent %d requests", len(requests))
+	}
+
+	srv.ResetRequests()
+	count, tx := db.Count()
+	if pageSizes, _ := searchRequests(srv); tx.Error != nil || count != 1201 || !reflect.DeepEqual(pageSizes, []int{1}) {
+		t.Errorf("Count = %d, page sizes %v, err %v", count, pageSizes, tx.Error)
+	}
+}
+
+func TestFirstNotFound(t *testing.T) {
+	db, srv, _ := newSearchTestDB(0)
+	defer srv.Close()
+
+	var dest map[string]interface{}
+	if tx := db.First(&dest); !errors.Is(tx.Error, ErrRecordNotFound) {
+		t.Errorf("First error = %v, want ErrRecordNotFound", tx.Error)
+	}
+	if count, tx := db.Count(); tx.Error != nil || count != 0 {
+		t.Errorf("Count = %d, err %v", count, tx.Error)
+	}
+}
+
+func TestSearchErrorKeepsApiResp(t *testing.T) {
+	_, srv, _ := newSearchTestDB(0)
+	defer srv.Close()
+	db := newServerDB(srv).Base(testAppToken).Table("tbl0000000000000")
+
+	var dest []map[string]interface{}
+	var first map[string]interface{}
+	_, countTx := db.Count()
+	for name, tx := range map[string]*DB{
+		"Find":  db.Find(&dest),
+		"First": db.First(&first),
+		"Count": countTx,
+		"Each":  db.Each(func(*larkbitable.AppTableRecord) error { return nil }),
+	} {
+		if tx.Error == nil || tx.ApiResp == nil || tx.CodeError == nil || tx.CodeError.Code != biormtest.CodeTableNotFound {
+			t.Errorf("%s error = %v, ApiResp = %v, CodeError = %v, want TableIdNotFound with response", name, tx.Error, tx.ApiResp, tx.CodeError)
+		}
+	}
+}
+
+func TestOffsetSkipsPages(t *testing.T) {
+	db, srv, ids := newSearchTestDB(1201)
+	defer srv.Close()
+
+	records, tx := db.Offset(1150).Limit(10).Records()
+	if tx.Error != nil || len(records) != 10 || *records[0].RecordId != ids[1150] {
+		t.Fatalf("Offset(1150).Limit(10) returned %d records, err %v", len(records), tx.Error)
+	}
+	if pageSizes, _ := searchRequests(srv); !reflect.DeepEqual(pageSizes, []int{500, 500, 150, 10}) {
+		t.Errorf("page sizes = %v, want [500 500 150 10]", pageSizes)
+	}
+
+	if records, tx := db.Offset(5000).Records(); tx.Error != nil || len(records) != 0 {
+		t.Errorf("Offset past the end returned %d records, err %v", len(records), tx.Error)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	db, srv, ids := newSearchTestDB(45)
+	defer srv.Close()
+
+	page, tx := db.Paginate(20, "")
+	if tx.Error != nil || len(page.Items) != 20 || !page.HasMore || page.NextCursor != "20" || page.Total != 45 {
+		t.Fatalf("first page = %+v, err %v", page, tx.Error)
+	}
+
+	page, tx = db.Paginate(20, page.NextCursor)
+	if tx.Error != nil || *page.Items[0].RecordId != ids[20] {
+		t.Fatalf("second page = %+v, err %v", page, tx.Error)
+	}
+
+	page, tx = db.Offset(40).Paginate(20, "")
+	if tx.Error != nil || len(page.Items) != 5 || *page.Items[0].RecordId != ids[40] || page.HasMore || page.NextCursor != "" {
+		t.Errorf("third page by offset = %+v, err %v", page, tx.Error)
+	}
+}
+
+func TestRequestsUseConfiguredDomain(t *testing.T) {
+	t.Run("client domain", func(t *testing.T) {
+		srv := biormtest.NewServer()
+		defer srv.Close()
+		testRequestsSentTo(t, newServerDB(srv), srv)
+	})
+
+	t.Run("Config.BaseUrl", func(t *testing.T) {
+		srv := biormtest.NewServer()
+		defer srv.Close()
+		other := biormtest.NewServer()
+		defer other.Close()
+
+		// lark.Client 指向 other，Config.BaseUrl 覆盖为 srv
+		db := newServerDB(other)
+		db.Config.BaseUrl = srv.URL + "/"
+		testRequestsSentTo(t, db, srv)
+		if requests := other.Requests(); len(requests) != 0 {
+			t.Errorf("requests sent to client domain: %+v", requests)
+		}
+	})
+}
+
+// testRequestsSentTo 依次调用各类接口，检查全部请求都发往 srv
+func testRequestsSentTo(t *testing.T, db *DB, srv *biormtest.Server) {
+	type task struct {
+		RecordId string `biorm:"record_id"`
+		Title    string `biorm:"名称"`
+	}
+	srv.AddApp(testAppToken, "项目管理")
+	srv.AddWikiNode("wiki", testAppToken)
+	srv.AddTable(testAppToken, testTableId, "任务")
+
+	// tenant_access_token 的请求不会被记录，但只有从同一个服务器获取的凭证才能通过校验
+	saved := task{Title: "写文档"}
+	table := db.Wiki("wiki").Table("任务")
+	if table.Error != nil {
+		t.Fatal(table.Error)
+	}
+	steps := []struct {
+		name string
+		run  func() *DB
+	}{
+		{"Meta", func() *DB { _, tx := table.Meta(); return tx }},
+		{"CreateTable", func() *DB { _, tx := db.Base(testAppToken).CreateTable("项目"); return tx }},
+		{"AddField", func() *DB { _, tx := table.AddField(&Field{Name: "名称", Type: FieldTypeText}); return tx }},
+		{"Fields", func() *DB { _, tx := table.Fields(); return tx }},
+		{"CreateView", func() *DB { _, tx := table.CreateView("全部", ""); return tx }},
+		{"Views", func() *DB { _, tx := table.Views(); return tx }},
+		{"Save", func() *DB { return table.Save(&saved) }},
+		{"Find", func() *DB { var tasks []task; return table.Where("名称 = ?", "写文档").Find(&tasks) }},
+		{"Update", func() *DB {
+			_, tx := table.Update(saved.RecordId, map[string]interface{}{"名称": "评审"})
+			return tx
+		}},
+		{"BatchGet", func() *DB { _, tx := table.BatchGet([]string{saved.RecordId}); return tx }},
+		{"Delete", func() *DB { _, tx := table.Delete(saved.RecordId); return tx }},
+	}
+	for _, step := range steps {
+		if tx := step.run(); tx.Error != nil {
+			t.Fatalf("%s: %v", step.name, tx.Error)
+		}
+	}
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	requests := srv.Requests()
+	if len(requests) < len(steps) || requests[0].Path != "/open-apis/wiki/v2/spaces/get_node" {
+		t.Fatalf("requests = %+v", requests)
+	}
+	for _, r := range requests {
+		if r.Host != host {
+			t.Errorf("%s %s sent to %s, want %s", r.Method, r.Path, r.Host, host)
+		}
+	}
+}
+
+func TestPaginationHonorsContext(t *testing.T) {
+	db, srv, _ := newSearchTestDB(1201)
+	defer srv.Close()
+	db.Config.RequestInterval = time.Hour
+
+	// 超时打断两页之间的等待
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, tx := db.WithContext(ctx).Records(); !errors.Is(tx.Error, context.DeadlineExceeded) {
+		t.Errorf("Records error = %v, want context.DeadlineExceeded", tx.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Records returned after %v", elapsed)
+	}
+	if pageSizes, _ := searchRequests(srv); len(pageSizes) != 1 {
+		t.Errorf("Records requested %d pages, want 1", len(pageSizes))
+	}
+
+	// 第一页处理过程中取消
+	srv.ResetRequests()
+	ctx, cancel = context.WithCancel(context.Background())
+	count := 0
+	tx := db.WithContext(ctx).Each(func(*larkbitable.AppTableRecord) error {
+		if count++; count == 1 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(tx.Error, context.Canceled) || count != 500 {
+		t.Errorf("Each visited %d records, err %v, want context.Canceled after the first page", count, tx.Error)
+	}
+	if pageSizes, _ := searchRequests(srv); len(pageSizes) != 1 {
+		t.Errorf("Each requested %d pages, want 1", len(pageSizes))
+	}
+
+	// 已取消的 context 不发起请求
+	srv.ResetRequests()
+	if _, tx := db.WithContext(ctx).Records(); !errors.Is(tx.Error, context.Canceled) {
+		t.Errorf("Records with cancelled context error = %v", tx.Error)
+	}
+	if pageSizes, _ := searchRequests(srv); len(pageSizes) != 0 {
+		t.Errorf("cancelled context requested %d pages", len(pageSizes))
+	}
+}
@@ -0,0 +1,159 @@
+package biorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TagName 模型字段使用的 struct tag 名称
+//
+// Usage:
+//
+//	type Task struct {
+//		RecordId string    `biorm:"record_id"` // 记录 ID
+//		Title    string    `biorm:"任务名称"`
+//		Tags     []string  `biorm:"标签"`
+//		Deadline time.Time `biorm:"截止日期"`
+//		Ignored  string    `biorm:"-"`
+//	}
+const TagName = "biorm"
+
+// RecordIdTag 用于标记保存记录 ID 的模型字段
+const RecordIdTag = "record_id"
+
+// schemaField 描述模型字段与多维表格字段之间的映射
+type schemaField struct {
+	Name       string            // 多维表格字段名
+	GoName     string            // 模型字段名
+	Index      []int             // 模型字段在结构体中的位置
+	Type       reflect.Type      // 模型字段类型
+	Settings   map[string]string // tag 中除字段名外的其余配置
+	IsRecordId bool              // 是否为记录 ID 字段
+}
+
+// modelSchema 描述一个模型结构体的全部字段映射
+type modelSchema struct {
+	Type          reflect.Type
+	Fields        []*schemaField
+	FieldsByName  map[string]*schemaField
+	RecordIdField *schemaField
+}
+
+var schemaCache sync.Map // map[reflect.Type]*modelSchema
+
+// parseSchema 解析模型结构体的 biorm tag，结果会被缓存
+func parseSchema(t reflect.Type) (*modelSchema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedModel, t)
+	}
+	if v, ok := schemaCache.Load(t); ok {
+		return v.(*modelSchema), nil
+	}
+
+	s := &modelSchema{Type: t, FieldsByName: make(map[string]*schemaField)}
+	if err := s.parseFields(t, nil); err != nil {
+		return nil, err
+	}
+
+	v, _ := schemaCache.LoadOrStore(t, s)
+	return v.(*modelSchema), nil
+}
+
+func (s *modelSchema) parseFields(t reflect.Type, index []int) error {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, tagged := sf.Tag.Lookup(TagName)
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		// 未设置 tag 的匿名结构体字段，展开其内部字段
+		if sf.Anonymous && !tagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := s.parseFields(ft, fieldIndex); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		// 只映射设置了 tag 的导出字段
+		if !tagged || sf.PkgPath != "" {
+			continue
+		}
+
+		name, settings := parseTag(tag)
+		if name == "" {
+			name = sf.Name
+		}
+		if _, ok := s.FieldsByName[name]; ok {
+			return fmt.Errorf("%w: %s.%s 与其他字段重复映射到 %s", ErrUnsupportedModel, t, sf.Name, name)
+		}
+
+		f := &schemaField{
+			Name:       name,
+			GoName:     sf.Name,
+			Index:      fieldIndex,
+			Type:       sf.Type,
+			Settings:   settings,
+			IsRecordId: name == RecordIdTag,
+		}
+		if f.IsRecordId {
+			if f.Type.Kind() != reflect.String {
+				return fmt.Errorf("%w: %s.%s 记录 ID 字段必须是 string 类型", ErrUnsupportedModel, t, sf.Name)
+			}
+			s.RecordIdField = f
+		}
+		s.Fields = append(s.Fields, f)
+		s.FieldsByName[name] = f
+	}
+	return nil
+}
+
+// parseTag 解析形如 `字段名;key;key:value` 的 tag
+func parseTag(tag string) (name string, settings map[string]string) {
+	settings = make(map[string]string)
+	parts := strings.Split(tag, ";")
+	name = strings.TrimSpace(parts[0])
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		key := strings.ToLower(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			settings[key] = strings.TrimSpace(kv[1])
+		} else {
+			settings[key] = key
+		}
+	}
+	return
+}
+
+// fieldValue 返回结构体中对应字段的值，必要时初始化嵌入的指针结构体
+func (f *schemaField) fieldValue(v reflect.Value) reflect.Value {
+	for i, idx := range f.Index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(idx)
+	}
+	return v
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"regexp"
+	"reflect"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,202 +34,198 @@ type Statement struct {
 	Idempotent  bool   // 是否幂等
 	ClientToken string // 幂等 uuid
 
+	AllowGlobalUpdate bool // 是否允许在没有任何条件时执行 Updates、Delete
+
+	// 分页查询
+	PageSize  int    // 每页记录数，默认为 MaxPageSize
+	PageToken string // 开始查询的 page_token
+	Limit     int    // 最多返回的记录数，为 0 时不限制
+	Offset    int    // 跳过的记录数
+
 	// 考虑需要
 	Dest interface{}
 }
 
 // BuildCondition 根据查询参数构建过滤条件
-func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) {
-	if stmt.Filter.Conditions == nil {
-		stmt.Filter.Conditions = make([]*larkbitable.Condition, 0)
+// query 为 func(*DB) *DB 时，函数内的 Where/Or 条件会作为一个条件组返回；
+// query 为 map[string]interface{} 或带 biorm tag 的结构体时，每个字段生成一个条件；
+// query 为 F() 构建的 Expr 时直接使用其条件
+func (stmt *Statement) BuildCondition(query interface{}, args ...interface{}) (filter larkbitable.FilterInfo) {
+	if fn, ok := query.(func(*DB) *DB); ok {
+		return stmt.buildGroup(fn)
 	}
 
-	if s, ok := query.(string); ok {
-		// if it is a number, then treats it as primary key
-		if s == "" && len(args) == 0 {
+	if e, ok := query.(*Expr); ok && e != nil {
+		query = *e
+	}
+	if e, ok := query.(Expr); ok {
+		if e.err != nil {
+			stmt.Error = e.err
 			return
 		}
+		return e.filter
+	}
 
-		if len(args) == 0 {
-			//re := regexp.MustCompile(`(?m)(?P<field>[^ ]+)\s+(?P<operator>isEmpty|isNotEmpty|isGreater|isGreaterEqual|isLess|isLessEqual|like|in|=|!=|>|<|>=|<=|<>|is null|is empty|is not null|is not empty|is|isNot|contains|doesNotContain|)\s*`)
-			re := regexp.MustCompile(`(?m)(?P<field>[^ ]+)\s+(?P<operator>isEmpty|isNotEmpty|is null|is not null|is empty|is not empty|isGreaterEqual|isLessEqual|isGreater|isLess|isNot|contains|doesNotContain|=|!=|>|<|>=|<=|<>|is|like|in)\s*`)
-			var match = re.FindStringSubmatch(s)
-			if len(match) == 0 {
-				stmt.Error = ErrInvalidWhereParamsLength
-				return
-			}
-			field := match[re.SubexpIndex("field")]
-			op := match[re.SubexpIndex("operator")]
-
-			switch op {
-			case "is", "=":
-				op = "is"
-			case "isNot", "!=", "<>": // 不等于（不支持日期字段，了解如何查询日期字段，参考日期字段填写说明）
-				op = "isNot"
-			case "contains": // 包含（不支持日期字段）
-				op = "contains"
-			case "doesNotContain": // 不包含（不支持日期字段）
-				op = "doesNotContain"
-			case "isEmpty", "is empty", "is null": // 为空
-				op = "isEmpty"
-			case "isNotEmpty", "is not empty", "is not null": // 不为空
-				op = "isNotEmpty"
-			case "isGreater", ">":
-				op = "isGreater"
-			case "isGreaterEqual", ">=":
-				op = "isGreaterEqual"
-			case "isLess", "<":
-				op = "isLess"
-			case "isLessEqual", "<=":
-				op = "isLessEqual"
-			case "like":
-				op = "like"
-			case "in":
-				op = "in"
-			default:
-				stmt.Error = fmt.Errorf("无法解析的 where condition operation: %s", op)
-			}
-			cond := &larkbitable.Condition{FieldName: &field, Operator: &op, Value: make([]string, 0)}
-			switch op {
-			case "is", "isNot", "contains", "doesNotContain", "isEmpty", "isNotEmpty", "isGreater", "isGreaterEqual", "isLess", "isLessEqual", "like", "in":
-			}
-			stmt.Filter.Conditions = append(stmt.Filter.Conditions, cond)
+	if m, ok := query.(map[string]interface{}); ok {
+		built, err := buildMapCondition(m)
+		if err != nil {
+			stmt.Error = err
 			return
 		}
+		return built
+	}
 
-		// 支持 ? 方式传参
-		if len(args) > 0 && strings.Contains(s, "?") {
-			// 问号需要和参数数量一致
-			if strings.Count(s, "?") != len(args) {
-				stmt.Error = ErrInvalidWhereParamsLength
-				return
-			}
+	if rv := reflect.Indirect(reflect.ValueOf(query)); rv.Kind() == reflect.Struct {
+		built, err := buildStructCondition(rv, args...)
+		if err != nil {
+			stmt.Error = err
+			return
+		}
+		return built
+	}
 
-			if strings.Count(s, "?") == 1 {
-				// 单个问号，直接替换
-
-				// 正则匹配：
-				// 要求满足以下 case 都能提取相同的结果 ["年龄", "=", "?"]:
-				// "年龄=?"
-				// "年龄 = ?"
-				// "年龄 =?"
-				// "年龄= ?"
-				// "年龄  = ?"
-				// " 年龄=? "
-				// 要求满足以下 case 都能提取相同的结果 ["linkin", "=", "?"]:
-				// "linkin=?"
-				// 中间的操作符可能满足如下情况
-				re := regexp.MustCompile(`(?m)(?P<field>[^ ]+)\s+(?P<operator>=|!=|>|<|>=|<=|<>|is null|is empty|is not null|is not empty|is|isNot|contains|doesNotContain|isEmpty|isNotEmpty|isGreater|isGreaterEqual|isLess|isLessEqual|like|in)\s+\?`)
-				var match = re.FindStringSubmatch(s)
-				if len(match) == 0 {
-					stmt.Error = ErrInvalidWhereParamsLength
-					return
-				}
-				field := match[re.SubexpIndex("field")]
-				op := match[re.SubexpIndex("operator")]
-				value := args[0]
-
-				switch op {
-				case "is", "=":
-					op = "is"
-				case "isNot", "!=", "<>": // 不等于（不支持日期字段，了解如何查询日期字段，参考日期字段填写说明）
-					op = "isNot"
-				case "contains": // 包含（不支持日期字段）
-					op = "contains"
-				case "doesNotContain": // 不包含（不支持日期字段）
-					op = "doesNotContain"
-				case "isEmpty", "is empty", "is null": // 为空
-					op = "isEmpty"
-				case "isNotEmpty", "is not empty", "is not null": // 不为空
-					op = "isNotEmpty"
-				case "isGreater", ">":
-					op = "isGreater"
-				case "isGreaterEqual", ">=":
-					op = "isGreaterEqual"
-				case "isLess", "<":
-					op = "isLess"
-				case "isLessEqual", "<=":
-					op = "isLessEqual"
-				case "like":
-					op = "like"
-				case "in":
-					op = "in"
-				default:
-					stmt.Error = fmt.Errorf("无法解析的 where condition operation: %s", op)
-				}
-				cond := &larkbitable.Condition{FieldName: &field, Operator: &op, Value: []string{}}
-				if value != nil {
-					switch value.(type) {
-					case string:
-						cond.Value = []string{value.(string)}
-					case []string:
-						cond.Value = value.([]string)
-					case []byte:
-						cond.Value = []string{string(value.([]byte))}
-					case int:
-						cond.Value = []string{fmt.Sprintf("%d", value.(int))}
-					case int8:
-						cond.Value = []string{fmt.Sprintf("%d", value.(int8))}
-					case int16:
-						cond.Value = []string{fmt.Sprintf("%d", value.(int16))}
-					case int32:
-						cond.Value = []string{fmt.Sprintf("%d", value.(int32))}
-					case int64:
-						cond.Value = []string{fmt.Sprintf("%d", value.(int64))}
-					case uint:
-						cond.Value = []string{fmt.Sprintf("%d", value.(uint))}
-					case uint8:
-						cond.Value = []string{fmt.Sprintf("%d", value.(uint8))}
-					case uint16:
-						cond.Value = []string{fmt.Sprintf("%d", value.(uint16))}
-					case uint32:
-						cond.Value = []string{fmt.Sprintf("%d", value.(uint32))}
-					case uint64:
-						cond.Value = []string{fmt.Sprintf("%d", value.(uint64))}
-					case float32:
-						cond.Value = []string{fmt.Sprintf("%f", value.(float32))}
-					case float64:
-						cond.Value = []string{fmt.Sprintf("%f", value.(float64))}
-					case bool:
-						cond.Value = []string{fmt.Sprintf("%t", value.(bool))}
-					case time.Time:
-						// 日期筛选时，operator 仅支持 is、isEmpty、isNotEmpty、isGreater、isLess 五个值。
-						if op == "isEmpty" || op == "isNotEmpty" {
-							// 当 operator 为 isEmpty或isNotEmpty 时，value 需填空值 "value":[]。
-							cond.Value = []string{}
-						} else if op == "is" || op == "isGreater" || op == "isLess" {
-							// 当 operator 为 is、isGreater 或 isLess 时，参考下表填写日期字段。
-							// 第二个元素虽然是时间戳，但是实际筛选时，会被转为文档时区当天的零点。
-							// 对于公式日期字段，第二个元素需要填写 yyyy/MM/dd 格式的日期文本，例如 2025/01/07
-							cond.Value = []string{"ExactDate", fmt.Sprintf("%d", value.(time.Time).UnixMilli())}
-						} else {
-							stmt.Error = fmt.Errorf("无法解析的 where condition operation: %s", op)
-						}
-					case map[string]interface{}:
-						if jsonValue, err := json.Marshal(value.(map[string]interface{})); err == nil {
-							cond.Value = []string{string(jsonValue)}
-						} else {
-							cond.Value = []string{fmt.Sprintf("%v", value)}
-						}
-					case struct{}:
-						if jsonValue, err := json.Marshal(value); err == nil {
-							cond.Value = []string{string(jsonValue)}
-						} else {
-							cond.Value = []string{fmt.Sprintf("%v", value)}
-						}
-					default:
-						cond.Value = []string{fmt.Sprintf("%v", value)}
-					}
-				}
-				stmt.Filter.Conditions = append(stmt.Filter.Conditions, cond)
-				return
-			} else {
-				stmt.Error = fmt.Errorf("查询 query 暂不支持多个 ? 符号：%s", query)
-				return
-			}
+	if s, ok := query.(string); ok {
+		if strings.TrimSpace(s) == "" && len(args) == 0 {
+			return
 		}
 
+		parsed, err := parseQueryFor(s, stmt.fieldTypeLookup(), args...)
+		if err != nil {
+			stmt.Error = err
+			return
+		}
+		return parsed
 	}
 	stmt.Error = fmt.Errorf("暂不支持的 query 类型：%s", query)
 	return
 }
+
+// encodeConditionValue 将查询参数转换为筛选条件的 value
+func encodeConditionValue(op string, value interface{}) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	switch value.(type) {
+	case string:
+		return []string{value.(string)}, nil
+	case []string:
+		return value.([]string), nil
+	case []byte:
+		return []string{string(value.([]byte))}, nil
+	case int:
+		return []string{fmt.Sprintf("%d", value.(int))}, nil
+	case int8:
+		return []string{fmt.Sprintf("%d", value.(int8))}, nil
+	case int16:
+		return []string{fmt.Sprintf("%d", value.(int16))}, nil
+	case int32:
+		return []string{fmt.Sprintf("%d", value.(int32))}, nil
+	case int64:
+		return []string{fmt.Sprintf("%d", value.(int64))}, nil
+	case uint:
+		return []string{fmt.Sprintf("%d", value.(uint))}, nil
+	case uint8:
+		return []string{fmt.Sprintf("%d", value.(uint8))}, nil
+	case uint16:
+		return []string{fmt.Sprintf("%d", value.(uint16))}, nil
+	case uint32:
+		return []string{fmt.Sprintf("%d", value.(uint32))}, nil
+	case uint64:
+		return []string{fmt.Sprintf("%d", value.(uint64))}, nil
+	case float32:
+		return []string{strconv.FormatFloat(float64(value.(float32)), 'f', -1, 32)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(value.(float64), 'f', -1, 64)}, nil
+	case bool:
+		return []string{fmt.Sprintf("%t", value.(bool))}, nil
+	case time.Time, DateValue:
+		// 日期筛选时，operator 仅支持 is、isEmpty、isNotEmpty、isGreater、isLess 五个值。
+		// 其它运算符需要转换为条件组合，见 dateFilter
+		d, _ := dateValueOf(value)
+		if op == "isEmpty" || op == "isNotEmpty" {
+			// 当 operator 为 isEmpty或isNotEmpty 时，value 需填空值 "value":[]。
+			return []string{}, nil
+		}
+		if d.end == nil && (op == "is" || d.period == "" && (op == "isGreater" || op == "isLess")) {
+			return d.values(), nil
+		}
+		return nil, fmt.Errorf("%w: %s %s", ErrUnsupportedDateOperator, op, d)
+	case Person, Link, Url, *Person, *Link, *Url:
+		// 人员字段使用人员 ID，关联字段使用关联记录 ID，超链接字段使用链接
+		return encodeConditionValue(op, conditionValueOf(reflect.ValueOf(value)))
+	}
+
+	switch rv := reflect.ValueOf(value); rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return []string{}, nil
+		}
+		return encodeConditionValue(op, rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		// 切片的每个元素作为一个值
+		values := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			encoded, err := encodeConditionValue(op, rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, encoded...)
+		}
+		return values, nil
+	case reflect.Map, reflect.Struct:
+		// 筛选条件的值只能是文本，map 与结构体（Person、Link、Url 除外）没有对应的编码
+		return nil, fmt.Errorf("%w: %T", ErrInvalidFieldValue, value)
+	default:
+		return []string{fmt.Sprintf("%v", value)}, nil
+	}
+}
+
+// buildGroup 在一个不带条件的实例上执行 fn，返回其构建的条件组
+func (stmt *Statement) buildGroup(fn func(*DB) *DB) larkbitable.FilterInfo {
+	g := stmt.DB.getInstance()
+	g.Statement.Filter = larkbitable.FilterInfo{}
+	g = fn(g)
+	if g == nil {
+		return larkbitable.FilterInfo{}
+	}
+	if g.hasError() {
+		stmt.Error = g.Error
+		return larkbitable.FilterInfo{}
+	}
+	return g.Statement.Filter
+}
+
+// AddFilter 使用 conjunction 将 filter 连接到当前的过滤条件上
+func (stmt *Statement) AddFilter(conjunction string, filter larkbitable.FilterInfo) {
+	combined, err := combineFilter(conjunction, stmt.Filter, filter)
+	if err != nil {
+		stmt.Error = err
+		return
+	}
+	stmt.Filter = combined
+}
+
+// buildSearchBody 构建查询记录接口的请求体
+func (stmt *Statement) buildSearchBody() map[string]interface{} {
+	body := make(map[string]interface{})
+	if stmt.ViewId != "" {
+		body["view_id"] = stmt.ViewId
+	}
+	if len(stmt.Selects) > 0 {
+		body["field_names"] = stmt.Selects
+	}
+	if len(stmt.Sort) > 0 {
+		body["sort"] = stmt.Sort
+	}
+	if filter := buildFilterBody(stmt.Filter); filter != nil {
+		body["filter"] = filter
+
+		// 打印整个filter内容
+		filterJSON, _ := json.Marshal(filter)
+		stmt.debugf("[Records] 完整filter: %s", string(filterJSON))
+	} else {
+		stmt.debugf("[Records] Filter条件为空")
+	}
+	body["automatic_fields"] = stmt.AutomaticFields
+	return body
+}
@@ -0,0 +1,428 @@
+package biorm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// 数据表 ID 由 tbl 前缀与 13 到 16 位字母、数字组成，例如 tbl0xe5g8PP3U3cS；
+// Table() 传入其它值时按数据表名称查找
+const (
+	tableIdPrefix    = "tbl"
+	tableIdMinSuffix = 13
+	tableIdMaxSuffix = 16
+)
+
+// metaCache 缓存多维表格的元数据，同一个 NewDB 创建的实例共享
+type metaCache struct {
+	tableIds sync.Map // appToken + "/" + 数据表名称 -> 数据表 ID
+	fields   sync.Map // appToken + "/" + 数据表 ID -> []*Field
+	viewIds  sync.Map // appToken + "/" + 数据表 ID + "/" + 视图名称 -> 视图 ID
+}
+
+// clear 删除全部缓存
+func (c *metaCache) clear() {
+	for _, m := range []*sync.Map{&c.tableIds, &c.fields, &c.viewIds} {
+		m.Range(func(key, value interface{}) bool {
+			m.Delete(key)
+			return true
+		})
+	}
+}
+
+// InvalidateCache 清空数据表 ID、字段列表与视图 ID 的缓存，同一个 NewDB 创建的实例共享这些缓存；
+// 在界面上或通过其它程序修改数据表、字段、视图后调用，下次使用时重新请求接口
+//
+// Usage:
+//
+//	db.InvalidateCache()
+func (db *DB) InvalidateCache() {
+	if db.cache != nil {
+		db.cache.clear()
+	}
+}
+
+func tableCacheKey(appToken, name string) string {
+	return appToken + "/" + name
+}
+
+// isTableId 判断是否为数据表 ID，"tbl" 开头的数据表名称（例如 "tbl_tasks"）按名称处理
+func isTableId(s string) bool {
+	return hasIdShape(s, tableIdPrefix, tableIdMinSuffix, tableIdMaxSuffix)
+}
+
+// hasIdShape 判断 s 是否由 prefix 与 min 到 max 位字母、数字组成
+func hasIdShape(s, prefix string, min, max int) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	suffix := s[len(prefix):]
+	if len(suffix) < min || len(suffix) > max {
+		return false
+	}
+	for _, c := range suffix {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveTableId 按名称查找数据表 ID，结果会被缓存
+func (db *DB) resolveTableId(name string) (string, error) {
+	if db.cache != nil {
+		if id, ok := db.cache.tableIds.Load(tableCacheKey(db.AppToken, name)); ok {
+			return id.(string), nil
+		}
+	}
+
+	tables, tx := db.Tables()
+	if tx.hasError() {
+		db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+		return "", tx.Error
+	}
+	for _, t := range tables {
+		if t.Name != nil && *t.Name == name && t.TableId != nil {
+			return *t.TableId, nil
+		}
+	}
+	return "", ErrTableNotFound
+}
+
+// cacheTable 缓存数据表名称与 ID 的对应关系，id 为空时删除缓存
+func (db *DB) cacheTable(name, id string) {
+	if db.cache == nil || name == "" {
+		return
+	}
+	if id == "" {
+		db.cache.tableIds.Delete(tableCacheKey(db.AppToken, name))
+		return
+	}
+	db.cache.tableIds.Store(tableCacheKey(db.AppToken, name), id)
+}
+
+// uncacheTableId 删除指定数据表 ID 的全部缓存
+func (db *DB) uncacheTableId(tableId string) {
+	if db.cache == nil {
+		return
+	}
+	db.cache.tableIds.Range(func(key, value interface{}) bool {
+		if value.(string) == tableId && strings.HasPrefix(key.(string), db.AppToken+"/") {
+			db.cache.tableIds.Delete(key)
+		}
+		return true
+	})
+}
+
+// Tables 获取多维表格中的全部数据表
+// Usage:
+//
+//	tables, tx := db.Base(appToken).Tables()
+func (db *DB) Tables() (data []*larkbitable.AppTable, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+
+	var pageToken string
+	for {
+		req := larkbitable.NewListAppTableReqBuilder().
+			AppToken(tx.AppToken).
+			PageToken(pageToken).
+			PageSize(100).
+			Build()
+
+		// 发起请求
+		var resp *larkbitable.ListAppTableResp
+		err := tx.execute(apiCall{
+			Method:     http.MethodGet,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables",
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTable.List(ctx, req)
+			return resp, err
+		})
+
+		// 处理错误
+		if !tx.checkResponse(resp, err) {
+			return
+		}
+		if resp.Data == nil {
+			break
+		}
+
+		for _, t := range resp.Data.Items {
+			if t != nil && t.Name != nil && t.TableId != nil {
+				tx.cacheTable(*t.Name, *t.TableId)
+			}
+		}
+		data = append(data, resp.Data.Items...)
+
+		if resp.Data.HasMore == nil || !*resp.Data.HasMore || resp.Data.PageToken == nil || *resp.Data.PageToken == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+		if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+			tx.Error = err
+			return
+		}
+	}
+	return
+}
+
+// CreateTable 新增一个数据表，返回的 tx 已选中新数据表
+// fields 为数据表的初始字段，第一个字段为索引字段；不传时使用默认字段
+// Usage:
+//
+//	tableId, tx := db.Base(appToken).CreateTable("任务")
+func (db *DB) CreateTable(name string, fields ...*larkbitable.AppTableCreateHeader) (tableId string, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+
+	table := larkbitable.NewReqTableBuilder().Name(name)
+	if len(fields) > 0 {
+		table.Fields(fields)
+	}
+	req := larkbitable.NewCreateAppTableReqBuilder().
+		AppToken(tx.AppToken).
+		Body(larkbitable.NewCreateAppTableReqBodyBuilder().
+			Table(table.Build()).
+			Build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.CreateAppTableResp
+	err := tx.execute(apiCall{
+		Method: http.MethodPost,
+		Path:   "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables",
+		Body:   req.Body,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTable.Create(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil || resp.Data.TableId == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+
+	tableId = *resp.Data.TableId
+	tx.TableId = tableId
+	tx.cacheTable(name, tableId)
+	return
+}
+
+// CreateTables 批量新增数据表，只设置数据表名称，返回的数据表 ID 与 names 顺序一致
+func (db *DB) CreateTables(names ...string) (tableIds []string, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+
+	tables := make([]*larkbitable.ReqTable, 0, len(names))
+	for _, name := range names {
+		tables = append(tables, larkbitable.NewReqTableBuilder().Name(name).Build())
+	}
+	req := larkbitable.NewBatchCreateAppTableReqBuilder().
+		AppToken(tx.AppToken).
+		Body(larkbitable.NewBatchCreateAppTableReqBodyBuilder().
+			Tables(tables).
+			Build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.BatchCreateAppTableResp
+	err := tx.execute(apiCall{
+		Method: http.MethodPost,
+		Path:   "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/batch_create",
+		Body:   req.Body,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTable.BatchCreate(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+
+	tableIds = resp.Data.TableIds
+	for i, id := range tableIds {
+		if i < len(names) {
+			tx.cacheTable(names[i], id)
+		}
+	}
+	tx.RowsAffected = int64(len(tableIds))
+	return
+}
+
+// RenameTable 重命名当前数据表
+// Usage:
+//
+//	tx := db.Base(appToken).Table("任务").RenameTable("任务（归档）")
+func (db *DB) RenameTable(name string) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	req := larkbitable.NewPatchAppTableReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).
+		Body(larkbitable.NewPatchAppTableReqBodyBuilder().
+			Name(name).
+			Build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.PatchAppTableResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodPatch,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId,
+		Body:       req.Body,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTable.Patch(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+
+	tx.uncacheTableId(tx.TableId)
+	tx.cacheTable(name, tx.TableId)
+	tx.RowsAffected = 1
+	return
+}
+
+// DropTable 删除当前数据表
+// Usage:
+//
+//	tx := db.Base(appToken).Table("任务").DropTable()
+func (db *DB) DropTable() (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	return tx.DropTables(tx.TableId)
+}
+
+// DropTables 批量删除数据表，tableIds 可以是数据表 ID 或名称，不传时不发起请求
+func (db *DB) DropTables(tableIds ...string) (tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if len(tableIds) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(tableIds))
+	for _, id := range tableIds {
+		if !isTableId(id) {
+			resolved, err := tx.resolveTableId(id)
+			if err != nil {
+				tx.Error = err
+				return
+			}
+			id = resolved
+		}
+		ids = append(ids, id)
+	}
+
+	var err error
+	var resp interface{}
+	if len(ids) == 1 {
+		req := larkbitable.NewDeleteAppTableReqBuilder().AppToken(tx.AppToken).TableId(ids[0]).Build()
+		err = tx.execute(apiCall{
+			Method:     http.MethodDelete,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + ids[0],
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTable.Delete(ctx, req)
+			return resp, err
+		})
+	} else {
+		req := larkbitable.NewBatchDeleteAppTableReqBuilder().
+			AppToken(tx.AppToken).
+			Body(larkbitable.NewBatchDeleteAppTableReqBodyBuilder().
+				TableIds(ids).
+				Build()).
+			Build()
+		err = tx.execute(apiCall{
+			Method:     http.MethodPost,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/batch_delete",
+			Body:       req.Body,
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTable.BatchDelete(ctx, req)
+			return resp, err
+		})
+	}
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+
+	for _, id := range ids {
+		tx.uncacheTableId(id)
+	}
+	tx.RowsAffected = int64(len(ids))
+	return
+}
@@ -0,0 +1,90 @@
+package biorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+// tableName 返回测试服务器中指定数据表的名称，数据表不存在时返回空字符串
+func tableName(srv *biormtest.Server, tableId string) string {
+	for _, table := range srv.Tables(testAppToken) {
+		if table.Id == tableId {
+			return table.Name
+		}
+	}
+	return ""
+}
+
+func TestTableManagement(t *testing.T) {
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	srv.AddTable(testAppToken, testTableId, "任务")
+	base := newServerDB(srv).Base(testAppToken)
+
+	if tx := base.Table("任务"); tx.Error != nil || tx.TableId != testTableId {
+		t.Fatalf("Table(name) = %q, err %v", tx.TableId, tx.Error)
+	}
+	if tx := base.Table("不存在"); !errors.Is(tx.Error, ErrTableNotFound) {
+		t.Errorf("Table(missing) error = %v, want ErrTableNotFound", tx.Error)
+	}
+
+	tableId, tx := base.CreateTable("项目")
+	if tx.Error != nil || tx.TableId != tableId || tableName(srv, tableId) != "项目" {
+		t.Fatalf("CreateTable = %q, err %v", tableId, tx.Error)
+	}
+
+	// 名称已缓存，不再请求数据表列表
+	srv.ResetRequests()
+	if tx := base.Table("项目").RenameTable("项目（归档）"); tx.Error != nil || tableName(srv, tableId) != "项目（归档）" {
+		t.Fatalf("RenameTable err %v, tables %v", tx.Error, srv.Tables(testAppToken))
+	}
+	if requests := srv.Requests(); len(requests) != 1 {
+		t.Errorf("requests = %v, want only the rename", requests)
+	}
+
+	if tx := base.Table("项目（归档）").DropTable(); tx.Error != nil || tx.RowsAffected != 1 {
+		t.Fatalf("DropTable err %v", tx.Error)
+	}
+	if tableName(srv, tableId) != "" {
+		t.Errorf("table %s was not deleted", tableId)
+	}
+	if tx := base.Table("项目（归档）"); !errors.Is(tx.Error, ErrTableNotFound) {
+		t.Errorf("dropped table still resolves: %v", tx.Error)
+	}
+}
+
+func TestTableIdOrName(t *testing.T) {
+	for s, want := range map[string]bool{
+		"tbl0xe5g8PP3U3cS":     true,
+		"tbl0000000000001":     true,
+		"tbl0xe5g8PP3U3cSabc":  true,
+		"tbl":                  false,
+		"tbl_tasks":            false,
+		"tbl0xe5g8PP3U3c-":     false,
+		"tbl0xe5g8PP3U3cSabcd": false,
+		"任务":                   false,
+	} {
+		if got := isTableId(s); got != want {
+			t.Errorf("isTableId(%q) = %v, want %v", s, got, want)
+		}
+	}
+
+	srv := biormtest.NewServer()
+	defer srv.Close()
+	srv.AddTable(testAppToken, testTableId, "tbl_tasks")
+	base := newServerDB(srv).Base(testAppToken)
+
+	if tx := base.Table("tbl_tasks"); tx.Error != nil || tx.TableId != testTableId {
+		t.Errorf("Table(tbl_tasks) = %q, err %v", tx.TableId, tx.Error)
+	}
+
+	srv.ResetRequests()
+	if tx := base.DropTables(); tx.Error != nil || tx.RowsAffected != 0 {
+		t.Errorf("DropTables() rows %d, err %v", tx.RowsAffected, tx.Error)
+	}
+	if requests := srv.Requests(); len(requests) != 0 || len(srv.Tables(testAppToken)) != 1 {
+		t.Errorf("DropTables() sent %v", requests)
+	}
+}
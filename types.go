@@ -0,0 +1,30 @@
+package biorm
+
+// Person 人员字段，对应多维表格的人员、创建人、修改人字段
+type Person struct {
+	Id     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	EnName string `json:"en_name,omitempty"`
+	Email  string `json:"email,omitempty"`
+}
+
+// Url 超链接字段
+type Url struct {
+	Text string `json:"text"`
+	Link string `json:"link"`
+}
+
+// Attachment 附件字段
+type Attachment struct {
+	FileToken string `json:"file_token"`
+	Name      string `json:"name,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+	Url       string `json:"url,omitempty"`
+	TmpUrl    string `json:"tmp_url,omitempty"`
+}
+
+// Link 单向关联、双向关联字段，保存关联记录的 ID
+type Link struct {
+	RecordIds []string `json:"link_record_ids"`
+}
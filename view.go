@@ -0,0 +1,485 @@
+package biorm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// 视图 ID 由 vew 前缀与 7 位字母、数字组成，例如 vewTpR1urY；View() 传入其它值时按视图名称查找
+const (
+	viewIdPrefix = "vew"
+	viewIdSuffix = 7
+)
+
+// isViewId 判断是否为视图 ID，"vew" 开头的视图名称（例如 "vew汇总"）按名称处理
+func isViewId(s string) bool {
+	return hasIdShape(s, viewIdPrefix, viewIdSuffix, viewIdSuffix)
+}
+
+// 视图类型
+const (
+	ViewTypeGrid    = "grid"    // 表格视图
+	ViewTypeKanban  = "kanban"  // 看板视图
+	ViewTypeGallery = "gallery" // 画册视图
+	ViewTypeGantt   = "gantt"   // 甘特视图
+	ViewTypeForm    = "form"    // 表单视图
+)
+
+func viewCacheKey(appToken, tableId, name string) string {
+	return appToken + "/" + tableId + "/" + name
+}
+
+// resolveViewId 按名称查找当前数据表中的视图 ID，结果会被缓存；没有同名视图时按视图 ID 查找
+func (db *DB) resolveViewId(name string) (string, error) {
+	if db.cache != nil {
+		if id, ok := db.cache.viewIds.Load(viewCacheKey(db.AppToken, db.TableId, name)); ok {
+			return id.(string), nil
+		}
+	}
+
+	views, tx := db.Views()
+	if tx.hasError() {
+		db.ApiResp, db.CodeError = tx.ApiResp, tx.CodeError
+		return "", tx.Error
+	}
+	for _, v := range views {
+		if v.ViewName != nil && *v.ViewName == name && v.ViewId != nil {
+			return *v.ViewId, nil
+		}
+	}
+	for _, v := range views {
+		if v.ViewId != nil && *v.ViewId == name {
+			return name, nil
+		}
+	}
+	return "", ErrViewNotFound
+}
+
+// cacheView 缓存视图名称与 ID 的对应关系
+func (db *DB) cacheView(name, id string) {
+	if db.cache == nil || name == "" || id == "" {
+		return
+	}
+	db.cache.viewIds.Store(viewCacheKey(db.AppToken, db.TableId, name), id)
+}
+
+// uncacheViewId 删除指定视图 ID 的全部缓存
+func (db *DB) uncacheViewId(viewId string) {
+	if db.cache == nil {
+		return
+	}
+	prefix := db.AppToken + "/" + db.TableId + "/"
+	db.cache.viewIds.Range(func(key, value interface{}) bool {
+		if value.(string) == viewId && strings.HasPrefix(key.(string), prefix) {
+			db.cache.viewIds.Delete(key)
+		}
+		return true
+	})
+}
+
+// Views 获取当前数据表的全部视图
+// Usage:
+//
+//	views, tx := db.Base(appToken).Table(tableId).Views()
+func (db *DB) Views() (data []*larkbitable.AppTableView, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+
+	var pageToken string
+	for {
+		req := larkbitable.NewListAppTableViewReqBuilder().
+			AppToken(tx.AppToken).TableId(tx.TableId).
+			PageToken(pageToken).
+			PageSize(100).
+			Build()
+
+		// 发起请求
+		var resp *larkbitable.ListAppTableViewResp
+		err := tx.execute(apiCall{
+			Method:     http.MethodGet,
+			Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/views",
+			Idempotent: true,
+		}, func(ctx context.Context) (interface{}, error) {
+			var err error
+			resp, err = tx.api().Bitable.V1.AppTableView.List(ctx, req)
+			return resp, err
+		})
+
+		// 处理错误
+		if !tx.checkResponse(resp, err) {
+			return
+		}
+		if resp.Data == nil {
+			break
+		}
+
+		for _, v := range resp.Data.Items {
+			if v != nil && v.ViewName != nil && v.ViewId != nil {
+				tx.cacheView(*v.ViewName, *v.ViewId)
+			}
+		}
+		data = append(data, resp.Data.Items...)
+
+		if resp.Data.HasMore == nil || !*resp.Data.HasMore || resp.Data.PageToken == nil || *resp.Data.PageToken == "" {
+			break
+		}
+		pageToken = *resp.Data.PageToken
+		if err := sleepContext(tx.Statement.Context, tx.Config.RequestInterval); err != nil {
+			tx.Error = err
+			return
+		}
+	}
+	return
+}
+
+// CreateView 在当前数据表中新增视图，viewType 为空时新增表格视图，返回的 tx 已选中新视图
+// Usage:
+//
+//	view, tx := db.Base(appToken).Table(tableId).CreateView("进行中的任务", biorm.ViewTypeGrid)
+func (db *DB) CreateView(name string, viewType string) (data *larkbitable.AppTableView, tx *DB) {
+	tx = db.getInstance()
+	if tx.hasError() {
+		return
+	}
+
+	if tx.AppToken == "" {
+		tx.Error = ErrAppTokenRequired
+		return
+	}
+	if tx.TableId == "" {
+		tx.Error = ErrTableIdRequired
+		return
+	}
+	if viewType == "" {
+		viewType = ViewTypeGrid
+	}
+
+	req := larkbitable.NewCreateAppTableViewReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).
+		ReqView(larkbitable.NewReqViewBuilder().
+			ViewName(name).
+			ViewType(viewType).
+			Build()).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.CreateAppTableViewResp
+	err := tx.execute(apiCall{
+		Method: http.MethodPost,
+		Path:   "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/views",
+		Body:   req.ReqView,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableView.Create(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil || resp.Data.View == nil || resp.Data.View.ViewId == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+
+	data = resp.Data.View
+	tx.ViewId = *data.ViewId
+	tx.cacheView(name, tx.ViewId)
+	tx.RowsAffected = 1
+	return
+}
+
+// GetView 获取当前视图的信息，包括筛选条件与隐藏字段
+func (db *DB) GetView() (data *larkbitable.AppTableView, tx *DB) {
+	tx = db.getInstance()
+	if !tx.requireView() {
+		return
+	}
+
+	req := larkbitable.NewGetAppTableViewReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).ViewId(tx.ViewId).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.GetAppTableViewResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodGet,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/views/" + tx.ViewId,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableView.Get(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	if resp.Data == nil || resp.Data.View == nil {
+		tx.Error = ErrResponseIsNil
+		return
+	}
+	return resp.Data.View, tx
+}
+
+// ViewFilter 获取当前视图的筛选条件，视图没有筛选条件时返回 nil
+func (db *DB) ViewFilter() (data *larkbitable.AppTableViewPropertyFilterInfo, tx *DB) {
+	view, tx := db.GetView()
+	if tx.hasError() || view.Property == nil {
+		return
+	}
+	return view.Property.FilterInfo, tx
+}
+
+// RenameView 重命名当前视图
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).View("进行中的任务").RenameView("进行中")
+func (db *DB) RenameView(name string) (tx *DB) {
+	tx = db.getInstance()
+	if !tx.requireView() {
+		return
+	}
+
+	tx = tx.patchView(larkbitable.NewPatchAppTableViewReqBodyBuilder().ViewName(name).Build())
+	if tx.hasError() {
+		return
+	}
+	tx.uncacheViewId(tx.ViewId)
+	tx.cacheView(name, tx.ViewId)
+	return
+}
+
+// UpdateViewFilter 使用接口的原始结构更新当前视图的筛选条件
+func (db *DB) UpdateViewFilter(filter *larkbitable.AppTableViewPropertyFilterInfo) (tx *DB) {
+	tx = db.getInstance()
+	if !tx.requireView() {
+		return
+	}
+
+	return tx.patchView(larkbitable.NewPatchAppTableViewReqBodyBuilder().
+		Property(larkbitable.NewAppTableViewPropertyBuilder().
+			FilterInfo(filter).
+			Build()).
+		Build())
+}
+
+// SaveViewFilter 将 Where、Or、Not 构建的查询条件保存为当前视图的筛选条件
+// 视图筛选只支持一层条件，使用条件组时返回 ErrFilterTooDeep；字段名称会被转换为字段 ID，
+// 单选、多选字段的选项名称会被转换为选项 ID。
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).View("进行中的任务").
+//		Where("状态 = ?", "进行中").Where("负责人 is not empty").
+//		SaveViewFilter()
+func (db *DB) SaveViewFilter() (tx *DB) {
+	tx = db.getInstance()
+	if !tx.requireView() {
+		return
+	}
+
+	filter, err := tx.buildViewFilter()
+	if err != nil {
+		tx.Error = err
+		return
+	}
+	return tx.UpdateViewFilter(filter)
+}
+
+// buildViewFilter 将查询条件转换为视图筛选条件
+func (db *DB) buildViewFilter() (*larkbitable.AppTableViewPropertyFilterInfo, error) {
+	f := db.Statement.Filter
+	if len(f.Children) > 0 {
+		return nil, ErrFilterTooDeep
+	}
+
+	fields, err := db.cachedFields()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*Field, len(fields))
+	for _, field := range fields {
+		byName[field.Name] = field
+	}
+
+	info := &larkbitable.AppTableViewPropertyFilterInfo{Conjunction: f.Conjunction}
+	if info.Conjunction == nil {
+		conjunction := conjunctionAnd
+		info.Conjunction = &conjunction
+	}
+	for _, c := range f.Conditions {
+		if c == nil || c.FieldName == nil || c.Operator == nil {
+			continue
+		}
+		field, ok := byName[*c.FieldName]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrFieldNotFound, *c.FieldName)
+		}
+
+		condition := &larkbitable.AppTableViewPropertyFilterInfoCondition{
+			FieldId:  &field.Id,
+			Operator: c.Operator,
+		}
+		if len(c.Value) > 0 {
+			value, err := viewFilterValue(field, *c.Operator, c.Value)
+			if err != nil {
+				return nil, err
+			}
+			condition.Value = &value
+		}
+		info.Conditions = append(info.Conditions, condition)
+	}
+	return info, nil
+}
+
+// viewFilterValue 编码视图筛选条件的值：
+//
+//	单选、多选：选项 ID 的 JSON 数组
+//	日期：取值类型与时间戳的 JSON 数组，例如 ["ExactDate","1736251200000"]
+//	人员、关联：open_id 或记录 ID 的 JSON 数组
+//	其余字段：值的 JSON 字符串，视图筛选只能保存一个值，多个值时返回 ErrInvalidFieldValue
+func viewFilterValue(field *Field, op string, values []string) (string, error) {
+	var v interface{}
+	switch field.Type {
+	case FieldTypeSingleSelect, FieldTypeMultiSelect:
+		ids := make([]string, 0, len(values))
+		for _, name := range values {
+			id := ""
+			for _, o := range field.Options {
+				if o.Name == name || o.Id == name {
+					id = o.Id
+					break
+				}
+			}
+			if id == "" {
+				return "", fmt.Errorf("%w: 字段 %s 没有选项 %s", ErrInvalidFieldValue, field.Name, name)
+			}
+			ids = append(ids, id)
+		}
+		v = ids
+
+	case FieldTypeDateTime, FieldTypeCreatedTime, FieldTypeModifiedTime,
+		FieldTypeUser, FieldTypeCreatedUser, FieldTypeModifiedUser, FieldTypeSingleLink, FieldTypeDuplexLink:
+		encoded, err := encodeFieldValue(field, op, values)
+		if err != nil {
+			return "", err
+		}
+		v = encoded
+
+	default:
+		switch {
+		case isDateMode(values[0]):
+			// 公式、查找引用字段的日期条件
+			v = values
+		case len(values) == 1:
+			v = values[0]
+		default:
+			return "", fmt.Errorf("%w: 视图筛选条件中 %s 字段 %s 只能有一个值，得到 %q",
+				ErrInvalidFieldValue, field.Type, field.Name, values)
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// DropView 删除当前视图
+// Usage:
+//
+//	tx := db.Base(appToken).Table(tableId).View("进行中的任务").DropView()
+func (db *DB) DropView() (tx *DB) {
+	tx = db.getInstance()
+	if !tx.requireView() {
+		return
+	}
+
+	req := larkbitable.NewDeleteAppTableViewReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).ViewId(tx.ViewId).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.DeleteAppTableViewResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodDelete,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/views/" + tx.ViewId,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableView.Delete(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	tx.uncacheViewId(tx.ViewId)
+	tx.ViewId = ""
+	tx.RowsAffected = 1
+	return
+}
+
+// patchView 更新当前视图
+func (db *DB) patchView(body *larkbitable.PatchAppTableViewReqBody) (tx *DB) {
+	tx = db
+	req := larkbitable.NewPatchAppTableViewReqBuilder().
+		AppToken(tx.AppToken).TableId(tx.TableId).ViewId(tx.ViewId).
+		Body(body).
+		Build()
+
+	// 发起请求
+	var resp *larkbitable.PatchAppTableViewResp
+	err := tx.execute(apiCall{
+		Method:     http.MethodPatch,
+		Path:       "/open-apis/bitable/v1/apps/" + tx.AppToken + "/tables/" + tx.TableId + "/views/" + tx.ViewId,
+		Body:       body,
+		Idempotent: true,
+	}, func(ctx context.Context) (interface{}, error) {
+		var err error
+		resp, err = tx.api().Bitable.V1.AppTableView.Patch(ctx, req)
+		return resp, err
+	})
+
+	// 处理错误
+	if !tx.checkResponse(resp, err) {
+		return
+	}
+	tx.RowsAffected = 1
+	return
+}
+
+// requireView 检查是否已选中多维表格、数据表与视图
+func (db *DB) requireView() bool {
+	switch {
+	case db.hasError():
+	case db.AppToken == "":
+		db.Error = ErrAppTokenRequired
+	case db.TableId == "":
+		db.Error = ErrTableIdRequired
+	case db.ViewId == "":
+		db.Error = ErrViewIdRequired
+	default:
+		return true
+	}
+	return false
+}
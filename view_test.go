@@ -0,0 +1,105 @@
+package biorm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/2015WUJI01/biorm/biormtest"
+)
+
+func TestViewManagement(t *testing.T) {
+	srv := newFieldServer()
+	defer srv.Close()
+	srv.AddView(testAppToken, testTableId, biormtest.View{Id: "vewAll", Name: "全部", Type: "grid"})
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldDate", Name: "日期", Type: 5, UiType: "DateTime"})
+	srv.AddField(testAppToken, testTableId, biormtest.Field{Id: "fldOwner", Name: "负责人", Type: 11, UiType: "User"})
+	db := newServerDB(srv)
+	table := db.Base(testAppToken).Table(testTableId)
+
+	views, tx := table.Views()
+	if tx.Error != nil || len(views) != 1 || *views[0].ViewId != "vewAll" {
+		t.Fatalf("Views() = %v, err %v", views, tx.Error)
+	}
+	if tx := table.View("全部"); tx.Error != nil || tx.ViewId != "vewAll" {
+		t.Errorf("View(全部) = %q, err %v", tx.ViewId, tx.Error)
+	}
+	srv.AddView(testAppToken, testTableId, biormtest.View{Id: "vewSummary", Name: "vew汇总", Type: "grid"})
+	if tx := table.View("vew汇总"); tx.Error != nil || tx.ViewId != "vewSummary" {
+		t.Errorf("View(vew汇总) = %q, err %v", tx.ViewId, tx.Error)
+	}
+	if isViewId("vew汇总") || !isViewId("vewTpR1urY") {
+		t.Error("isViewId does not match the view id shape")
+	}
+	if tx := table.View("不存在"); !errors.Is(tx.Error, ErrViewNotFound) {
+		t.Errorf("View(不存在) err = %v", tx.Error)
+	}
+	if tx := db.Base(testAppToken).View("全部"); !errors.Is(tx.Error, ErrTableIdRequired) {
+		t.Errorf("View without table err = %v", tx.Error)
+	}
+
+	view, tx := table.CreateView("进行中", "")
+	viewId := tx.ViewId
+	if tx.Error != nil || tx.ViewId == "" || *view.ViewType != ViewTypeGrid {
+		t.Fatalf("CreateView() = %v, err %v", view, tx.Error)
+	}
+	if tx := table.View("进行中").RenameView("进行中的任务"); tx.Error != nil {
+		t.Fatalf("RenameView() err = %v", tx.Error)
+	}
+	if tx := table.View("进行中的任务"); tx.ViewId != viewId {
+		t.Errorf("View after rename = %q, err %v", tx.ViewId, tx.Error)
+	}
+
+	tx = table.View(viewId).Where("状态 = ?", "进行中").Where("名称 is not empty").SaveViewFilter()
+	if tx.Error != nil {
+		t.Fatalf("SaveViewFilter() err = %v", tx.Error)
+	}
+	filter, tx := table.View(viewId).ViewFilter()
+	if tx.Error != nil || filter == nil || len(filter.Conditions) != 2 {
+		t.Fatalf("ViewFilter() = %+v, err %v", filter, tx.Error)
+	}
+	if c := filter.Conditions[0]; *c.FieldId != "fldStatus" || *c.Operator != "is" || *c.Value != `["opt1"]` {
+		t.Errorf("condition = %s %s %s", *c.FieldId, *c.Operator, *c.Value)
+	}
+	if c := filter.Conditions[1]; *c.FieldId != "fldName" || *c.Operator != "isNotEmpty" || c.Value != nil {
+		t.Errorf("condition = %+v", c)
+	}
+
+	// 日期保留取值类型与时间戳，人员的多个值全部保留
+	tx = table.View(viewId).Where("日期 = ?", "1736251200000").Where(F("负责人").Contains("ou_1", "ou_2")).SaveViewFilter()
+	if tx.Error != nil {
+		t.Fatalf("SaveViewFilter() err = %v", tx.Error)
+	}
+	filter, tx = table.View(viewId).ViewFilter()
+	if tx.Error != nil || filter == nil || len(filter.Conditions) != 2 {
+		t.Fatalf("ViewFilter() = %+v, err %v", filter, tx.Error)
+	}
+	if c := filter.Conditions[0]; *c.FieldId != "fldDate" || *c.Value != `["ExactDate","1736251200000"]` {
+		t.Errorf("date condition = %s %s %s", *c.FieldId, *c.Operator, *c.Value)
+	}
+	if c := filter.Conditions[1]; *c.FieldId != "fldOwner" || *c.Operator != "contains" || *c.Value != `["ou_1","ou_2"]` {
+		t.Errorf("user condition = %s %s %s", *c.FieldId, *c.Operator, *c.Value)
+	}
+	tx = table.View(viewId).Where(F("名称").Contains("a", "b")).SaveViewFilter()
+	if !errors.Is(tx.Error, ErrInvalidFieldValue) {
+		t.Errorf("multi-value text SaveViewFilter() err = %v, want ErrInvalidFieldValue", tx.Error)
+	}
+
+	tx = table.View(viewId).Where("状态 = ?", "进行中").Or("价格 > ?", 1).Where("名称 = ?", "a").SaveViewFilter()
+	if !errors.Is(tx.Error, ErrFilterTooDeep) {
+		t.Errorf("nested SaveViewFilter() err = %v", tx.Error)
+	}
+	tx = table.View(viewId).Where("状态 = ?", "已取消").SaveViewFilter()
+	if !errors.Is(tx.Error, ErrInvalidFieldValue) {
+		t.Errorf("unknown option SaveViewFilter() err = %v", tx.Error)
+	}
+
+	if tx := table.View("进行中的任务").DropView(); tx.Error != nil || tx.ViewId != "" {
+		t.Fatalf("DropView() err = %v", tx.Error)
+	}
+	if views := srv.Views(testAppToken, testTableId); len(views) != 2 {
+		t.Errorf("views after drop = %v", views)
+	}
+	if tx := table.View("进行中的任务"); !errors.Is(tx.Error, ErrViewNotFound) {
+		t.Errorf("View after drop err = %v", tx.Error)
+	}
+}
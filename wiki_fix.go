@@ -1,13 +1,12 @@
 package biorm
 
 import (
-	"log"
 	"strings"
 )
 
 // FixedWiki 修复原始Wiki方法中的空指针问题
 func (db *DB) FixedWiki(appToken string, args ...interface{}) (tx *DB) {
-	log.Printf("[FixedWiki调试] 开始处理appToken=%s", appToken)
+	db.debugf("[FixedWiki] 开始处理appToken=%s", appToken)
 
 	// 复制当前实例
 	tx = db.getInstance()
@@ -33,13 +32,13 @@ func (db *DB) FixedWiki(appToken string, args ...interface{}) (tx *DB) {
 		tx.Statement.Filter.Conditions = nil
 	}
 
-	log.Printf("[FixedWiki调试] 设置appToken=%s", tx.AppToken)
+	tx.debugf("[FixedWiki] 设置appToken=%s", tx.AppToken)
 	return tx
 }
 
 // FixedWikiTable 修复原始WikiTable方法中的空指针问题
 func (db *DB) FixedWikiTable(combinedId string, args ...interface{}) (tx *DB) {
-	log.Printf("[FixedWikiTable调试] 接收combinedId=%s", combinedId)
+	db.debugf("[FixedWikiTable] 接收combinedId=%s", combinedId)
 
 	// 解析combined ID
 	parts := strings.Split(combinedId, ".")
@@ -49,7 +48,7 @@ func (db *DB) FixedWikiTable(combinedId string, args ...interface{}) (tx *DB) {
 	}
 
 	// 调用修复后的Wiki方法和Table方法
-	log.Printf("[FixedWikiTable调试] 解析成功: appToken=%s, tableId=%s", parts[0], parts[1])
+	db.debugf("[FixedWikiTable] 解析成功: appToken=%s, tableId=%s", parts[0], parts[1])
 	tx = db.FixedWiki(parts[0]).Table(parts[1])
 
 	return tx